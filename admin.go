@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// registerAdminRoutes mounts the /admin group behind bearer-token auth. The
+// group is skipped entirely when no ADMIN_TOKEN is configured.
+func registerAdminRoutes(e *echo.Echo) {
+	token := cfg().AdminToken
+	if token == "" {
+		return
+	}
+
+	admin := e.Group("/admin", middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+		return subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1, nil
+	}))
+
+	admin.POST("/reload", ReloadConfig)
+	admin.GET("/config", GetConfig)
+
+	// rewrites stored IDs, so never exposed in production
+	if cfg().Env == "development" {
+		admin.POST("/repair", RepairUsers, ResolveTenant)
+	}
+}
+
+// ConfigView is the effective configuration as reported by GET
+// /admin/config. Secrets only show whether they are set.
+type ConfigView struct {
+	*Config
+	AdminToken string `json:"admin_token" example:"[REDACTED]"`
+	// Storage names the user store backend.
+	Storage string `json:"storage" example:"memory"`
+}
+
+// redacted stands in for a secret that is set.
+const redacted = "[REDACTED]"
+
+// GetConfig godoc
+// @Summary      Show effective configuration
+// @Description  Returns the configuration in effect, including reloaded values. Secrets are shown as "[REDACTED]" when set and empty otherwise.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  ConfigView
+// @Failure      401  {object}  map[string]string
+// @Router       /admin/config [get]
+func GetConfig(c echo.Context) error {
+	conf := cfg()
+	view := ConfigView{Config: conf, Storage: "memory"}
+	if conf.AdminToken != "" {
+		view.AdminToken = redacted
+	}
+	return c.JSON(http.StatusOK, view)
+}
+
+// ReloadConfig godoc
+// @Summary      Reload configuration
+// @Description  Re-reads the hot-reloadable settings (LOG_LEVEL, DEFAULT_PAGE_LIMIT, MAX_PAGE_LIMIT) from the environment and applies them
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  Config
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Router       /admin/reload [post]
+func ReloadConfig(c echo.Context) error {
+	loaded, err := loadConfig()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	// only the hot-reloadable subset changes, the rest stays as booted
+	next := *cfg()
+	next.LogLevel = loaded.LogLevel
+	next.DefaultPageLimit = loaded.DefaultPageLimit
+	next.MaxPageLimit = loaded.MaxPageLimit
+	applyConfig(c.Echo(), &next)
+
+	c.Logger().Infof("configuration reloaded: log_level=%s default_page_limit=%d max_page_limit=%d",
+		next.LogLevel, next.DefaultPageLimit, next.MaxPageLimit)
+	return c.JSON(http.StatusOK, next)
+}
+
+// applyConfig makes conf the effective configuration and pushes the parts
+// that live outside of it (the logger level) into e.
+func applyConfig(e *echo.Echo, conf *Config) {
+	config.Store(conf)
+	e.Logger.SetLevel(logLevels[conf.LogLevel])
+}
+
+// RepairUsers godoc
+// @Summary      Check and repair the dataset
+// @Description  Scans the tenant's users for duplicate or non-positive IDs, negative ages, blank names and names that collide case-insensitively. Bad IDs are moved to fresh ones; the other anomalies are only reported.
+// @Description  Only registered when APP_ENV is development. With dry_run=true the report is produced but nothing changes.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        dry_run  query     bool  false  "Report without fixing"
+// @Success      200      {object}  store.RepairReport
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Router       /admin/repair [post]
+func RepairUsers(c echo.Context) error {
+	dryRun, err := isDryRun(c)
+	if err != nil {
+		return invalidDryRun(c)
+	}
+
+	done := timeStore(c)
+	report := usersFor(c).Repair(dryRun)
+	done()
+
+	if !dryRun && len(report.Anomalies) > 0 {
+		c.Logger().Warnf("repair: %d anomalies in %d users", len(report.Anomalies), report.Scanned)
+	}
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-echo/store"
+)
+
+// adminClient is a test client with the admin routes enabled.
+func adminClient(t *testing.T) *testClient {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.AdminToken = "secret"
+	})
+	return tc
+}
+
+func TestReloadConfig(t *testing.T) {
+	tc := adminClient(t)
+
+	expectStatus(t, tc.Do(http.MethodPost, "/admin/reload", ""), http.StatusBadRequest)
+	expectStatus(t, tc.Do(http.MethodPost, "/admin/reload", "", "Authorization", "Bearer wrong"), http.StatusUnauthorized)
+
+	t.Setenv("MAX_PAGE_LIMIT", "50")
+	expectStatus(t, tc.Do(http.MethodPost, "/admin/reload", "", "Authorization", "Bearer secret"), http.StatusOK)
+	if got := cfg().MaxPageLimit; got != 50 {
+		t.Errorf("MaxPageLimit %d after reload, want 50", got)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users?limit=60", ""), http.StatusBadRequest)
+}
+
+func TestGetConfig(t *testing.T) {
+	tc := adminClient(t)
+
+	expectStatus(t, tc.Do(http.MethodGet, "/admin/config", "", "Authorization", "Bearer wrong"), http.StatusUnauthorized)
+
+	rec := tc.Do(http.MethodGet, "/admin/config", "", "Authorization", "Bearer secret")
+	expectStatus(t, rec, http.StatusOK)
+	view := decode[map[string]any](t, rec)
+	if view["admin_token"] != redacted || view["storage"] != "memory" || view["max_page_limit"] != float64(cfg().MaxPageLimit) {
+		t.Errorf("config %s", rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), `"secret"`) {
+		t.Errorf("token leaked: %s", rec.Body)
+	}
+}
+
+func TestAdminRoutesOffWithoutToken(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	expectStatus(t, tc.Do(http.MethodGet, "/admin/config", "", "Authorization", "Bearer "), http.StatusNotFound)
+}
+
+func TestRepairRoute(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.AdminToken = "secret"
+		conf.Seed = []store.User{
+			{ID: 1, Name: "Agus", Age: 15},
+			{ID: 1, Name: "Bagus", Age: 25},
+		}
+	})
+	auth := []string{"Authorization", "Bearer secret"}
+
+	expectStatus(t, tc.Do(http.MethodPost, "/admin/repair", "", "Authorization", "Bearer wrong"), http.StatusUnauthorized)
+	rec := tc.Do(http.MethodPost, "/admin/repair?dry_run=true", "", auth...)
+	expectStatus(t, rec, http.StatusOK)
+	if report := decode[store.RepairReport](t, rec); len(report.Anomalies) != 1 || !report.DryRun {
+		t.Errorf("dry run %s", rec.Body)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users/2", ""), http.StatusNotFound)
+
+	expectStatus(t, tc.Do(http.MethodPost, "/admin/repair", "", auth...), http.StatusOK)
+	if u := tc.GetUser(2); u.Name != "Bagus" {
+		t.Errorf("user 2 is %+v, want Bagus moved there", u)
+	}
+}
+
+func TestRepairRouteOffInProduction(t *testing.T) {
+	t.Setenv("APP_ENV", "production")
+	tc := adminClient(t)
+
+	expectStatus(t, tc.Do(http.MethodPost, "/admin/repair", "", "Authorization", "Bearer secret"), http.StatusNotFound)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BulkUpdateRequest applies one set of changes to every user matching the
+// filter.
+type BulkUpdateRequest struct {
+	Filter  UserFilter      `json:"filter"`
+	Changes store.UserPatch `json:"changes"`
+}
+
+// BulkUpdateResult reports which users a bulk update changed.
+type BulkUpdateResult struct {
+	Updated int   `json:"updated" example:"2"`
+	IDs     []int `json:"ids" example:"1,3"`
+}
+
+// BulkUpdateUsers godoc
+// @Summary      Update all users matching a filter
+// @Description  Applies the changes to every user matching the filter in one step: either all matches are updated or, on a conflict, none are.
+// @Description  Renaming more than one user to the same name is a conflict. With dry_run=true the matches are reported but nothing is stored.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      BulkUpdateRequest  true   "Filter and changes"
+// @Param        dry_run  query     bool               false  "Preview without committing"
+// @Success      200      {object}  BulkUpdateResult
+// @Failure      400      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      422      {object}  map[string]string
+// @Router       /users/bulk-update [post]
+func BulkUpdateUsers(c echo.Context) error {
+	dryRun, err := isDryRun(c)
+	if err != nil {
+		return invalidDryRun(c)
+	}
+
+	var req BulkUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": bindErrorMessage(err)})
+	}
+
+	if err := validateBulkChanges(c, &req); err != nil {
+		return validationError(c, err)
+	}
+	if req.Changes.Name == nil && req.Changes.Age == nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.Map{"error": "changes must set at least one field"})
+	}
+	if req.Filter.invertedRange() {
+		return c.JSON(http.StatusUnprocessableEntity, echo.Map{"error": "min_age must not be greater than max_age"})
+	}
+
+	done := timeStore(c)
+	updated, err := usersFor(c).PatchMatching(req.Filter.matches, req.Changes, dryRun)
+	done()
+	if err != nil {
+		return storeError(c, err)
+	}
+
+	result := BulkUpdateResult{Updated: len(updated), IDs: make([]int, 0, len(updated))}
+	for _, u := range updated {
+		result.IDs = append(result.IDs, u.ID)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// BulkSetRequest sets one field to the same value on every listed user.
+type BulkSetRequest struct {
+	IDs   []int           `json:"ids" validate:"required,min=1,max=100" example:"1,3"`
+	Field string          `json:"field" validate:"required" example:"age"`
+	Value json.RawMessage `json:"value" swaggertype:"object"`
+}
+
+// BulkSetResult reports which listed users a bulk set changed and which
+// do not exist.
+type BulkSetResult struct {
+	Updated  []int `json:"updated" example:"1,3"`
+	NotFound []int `json:"not_found" example:"7"`
+}
+
+// BulkSetUsers godoc
+// @Summary      Set one field on listed users
+// @Description  Sets field to value on every user in ids in one step: either all of them are updated or, on a conflict, none are. IDs that do not exist are reported in not_found and skipped.
+// @Description  The value is checked against the same rules as PATCH /users/{id}; read-only fields such as id are rejected. With dry_run=true nothing is stored.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      BulkSetRequest  true   "IDs, field and value"
+// @Param        dry_run  query     bool            false  "Preview without committing"
+// @Success      200      {object}  BulkSetResult
+// @Failure      400      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      422      {object}  map[string]string
+// @Router       /users/bulk-set [post]
+func BulkSetUsers(c echo.Context) error {
+	dryRun, err := isDryRun(c)
+	if err != nil {
+		return invalidDryRun(c)
+	}
+
+	var req BulkSetRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": bindErrorMessage(err)})
+	}
+
+	if err := validateRequest(c, &req); err != nil {
+		return validationError(c, err)
+	}
+
+	path := "/" + req.Field
+	if immutablePaths[path] {
+		return c.JSON(http.StatusUnprocessableEntity, echo.Map{"error": fmt.Sprintf("field %s is read-only", req.Field)})
+	}
+	if _, ok := requiredPaths[path]; !ok {
+		return c.JSON(http.StatusUnprocessableEntity, echo.Map{"error": fmt.Sprintf("unknown field %q", req.Field)})
+	}
+	if len(req.Value) == 0 || string(req.Value) == "null" {
+		return c.JSON(http.StatusUnprocessableEntity, echo.Map{"error": "value is required"})
+	}
+
+	var patch store.UserPatch
+	if err := applyPatchValue(&patch, JSONPatchOp{Op: "set", Path: path, Value: req.Value}); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if err := validateBulkChanges(c, &patch); err != nil {
+		return validationError(c, err)
+	}
+
+	listed := make(map[int]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		listed[id] = true
+	}
+
+	done := timeStore(c)
+	updated, err := usersFor(c).PatchMatching(func(u store.User) bool { return listed[u.ID] }, patch, dryRun)
+	done()
+	if err != nil {
+		return storeError(c, err)
+	}
+
+	result := BulkSetResult{Updated: make([]int, 0, len(updated)), NotFound: []int{}}
+	for _, u := range updated {
+		result.Updated = append(result.Updated, u.ID)
+		delete(listed, u.ID)
+	}
+	for _, id := range req.IDs {
+		if listed[id] {
+			result.NotFound = append(result.NotFound, id)
+			// report duplicates in ids once
+			delete(listed, id)
+		}
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestBulkUpdateRenameToOwnNameInOtherCase(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users/bulk-update", `{"filter":{"name":"agus","max_age":20},"changes":{"name":"AGUS"}}`)
+	expectStatus(t, rec, http.StatusOK)
+	if got := tc.GetUser(1).Name; got != "AGUS" {
+		t.Errorf("name %q, want AGUS", got)
+	}
+}
+
+func TestBulkUpdateRenameOntoOtherUser(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users/bulk-update", `{"filter":{"name":"agus","max_age":20},"changes":{"name":"Bagus"}}`)
+	expectStatus(t, rec, http.StatusConflict)
+}
+
+func TestBulkSetRenameToOwnNameInOtherCase(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users/bulk-set", `{"ids":[1],"field":"name","value":"AGUS"}`)
+	expectStatus(t, rec, http.StatusOK)
+	if got := tc.GetUser(1).Name; got != "AGUS" {
+		t.Errorf("name %q, want AGUS", got)
+	}
+}
+
+func TestBulkUpdateByFilter(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users/bulk-update?dry_run=true", `{"filter":{"min_age":20},"changes":{"age":40}}`)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decode[BulkUpdateResult](t, rec); got.Updated != 2 || !slices.Equal(got.IDs, []int{2, 3}) {
+		t.Errorf("dry run %+v, want users 2 and 3", got)
+	}
+	if u := tc.GetUser(2); u.Age != 25 {
+		t.Errorf("dry run stored age %d", u.Age)
+	}
+
+	rec = tc.Do(http.MethodPost, "/users/bulk-update", `{"filter":{"min_age":20},"changes":{"age":40}}`)
+	expectStatus(t, rec, http.StatusOK)
+	for id, want := range map[int]int{1: 15, 2: 40, 3: 40} {
+		if u := tc.GetUser(id); u.Age != want {
+			t.Errorf("user %d age %d, want %d", id, u.Age, want)
+		}
+	}
+
+	// two users cannot both take one name, and neither is renamed
+	expectStatus(t, tc.Do(http.MethodPost, "/users/bulk-update", `{"filter":{"min_age":20},"changes":{"name":"Dewi"}}`), http.StatusConflict)
+	expectStatus(t, tc.Do(http.MethodGet, "/users/by-name/Dewi", ""), http.StatusNotFound)
+
+	expectStatus(t, tc.Do(http.MethodPost, "/users/bulk-update", `{"filter":{},"changes":{}}`), http.StatusUnprocessableEntity)
+}
+
+func TestBulkSetUsers(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users/bulk-set", `{"ids":[1,3,42],"field":"age","value":42}`)
+	expectStatus(t, rec, http.StatusOK)
+	got := decode[BulkSetResult](t, rec)
+	if !slices.Equal(got.Updated, []int{1, 3}) || !slices.Equal(got.NotFound, []int{42}) {
+		t.Errorf("result %+v, want updated [1 3], not found [42]", got)
+	}
+	for id, want := range map[int]int{1: 42, 2: 25, 3: 42} {
+		if u := tc.GetUser(id); u.Age != want {
+			t.Errorf("user %d age %d, want %d", id, u.Age, want)
+		}
+	}
+
+	for _, body := range []string{
+		`{"ids":[1,2],"field":"age","value":-1}`,
+		`{"ids":[1,2],"field":"name","value":""}`,
+		`{"ids":[1],"field":"id","value":9}`,
+		`{"ids":[1],"field":"created_at","value":"2026-01-01T00:00:00Z"}`,
+		`{"ids":[1],"field":"email","value":"a@b.c"}`,
+	} {
+		expectStatus(t, tc.Do(http.MethodPost, "/users/bulk-set", body), http.StatusUnprocessableEntity)
+	}
+	expectStatus(t, tc.Do(http.MethodPost, "/users/bulk-set", `{"ids":[1],"field":"age","value":"old"}`), http.StatusBadRequest)
+	// two users cannot share a name, so neither changes
+	expectStatus(t, tc.Do(http.MethodPost, "/users/bulk-set", `{"ids":[1,2],"field":"name","value":"Dewi"}`), http.StatusConflict)
+	if u := tc.GetUser(1); u.Name != "Agus" {
+		t.Errorf("user 1 renamed to %q", u.Name)
+	}
+}
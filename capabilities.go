@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Capabilities advertises what this deployment supports, derived from the
+// effective configuration, so clients can adapt without hard-coding it.
+type Capabilities struct {
+	Pagination PaginationCapabilities `json:"pagination"`
+	// Auth lists the authentication schemes in use: "bearer" for /admin
+	// when an admin token is set.
+	Auth []string `json:"auth" example:"bearer"`
+	// TenantHeader names the header selecting the tenant; Required is set
+	// in strict mode.
+	TenantHeader TenantHeaderCapability `json:"tenant_header"`
+	// Formats are the media types the API can produce or accept.
+	Formats []string `json:"formats" example:"application/json,text/vcard"`
+	// Features reports each DISABLED_FEATURES flag as enabled or not.
+	Features map[string]bool `json:"features"`
+	// Idempotency is set when writes accept an Idempotency-Key.
+	Idempotency bool `json:"idempotency" example:"true"`
+	Gzip        bool `json:"gzip" example:"false"`
+	H2C         bool `json:"h2c" example:"false"`
+	Swagger     bool `json:"swagger" example:"true"`
+	// MaxURLLength is the longest accepted URL in bytes; 0 means no limit.
+	MaxURLLength int `json:"max_url_length" example:"2048"`
+}
+
+// PaginationCapabilities describes how lists are paged.
+type PaginationCapabilities struct {
+	// Modes are "page" (page and limit parameters) and "range" (a Range:
+	// items= header on GET /users).
+	Modes        []string `json:"modes" example:"page,range"`
+	DefaultLimit int      `json:"default_limit" example:"20"`
+	MaxLimit     int      `json:"max_limit" example:"100"`
+}
+
+// TenantHeaderCapability describes the tenant header.
+type TenantHeaderCapability struct {
+	Name     string `json:"name" example:"X-Tenant-ID"`
+	Required bool   `json:"required" example:"false"`
+}
+
+// GetCapabilities godoc
+// @Summary      API capabilities
+// @Description  Lists the optional features, formats, auth schemes and limits in effect, derived from the configuration
+// @Tags         meta
+// @Produce      json
+// @Success      200  {object}  Capabilities
+// @Router       /capabilities [get]
+func GetCapabilities(c echo.Context) error {
+	conf := cfg()
+
+	caps := Capabilities{
+		Pagination: PaginationCapabilities{
+			Modes:        []string{"page", "range"},
+			DefaultLimit: conf.DefaultPageLimit,
+			MaxLimit:     conf.MaxPageLimit,
+		},
+		Auth:         []string{},
+		TenantHeader: TenantHeaderCapability{Name: conf.RequiredHeader, Required: conf.StrictMode},
+		Formats:      []string{echo.MIMEApplicationJSON, mimeProblemJSON, mimeJSONPatch, mimeVCard},
+		Features:     make(map[string]bool, len(features)),
+		Idempotency:  conf.IdempotencyTTL > 0,
+		Gzip:         conf.Gzip,
+		H2C:          conf.H2C,
+		Swagger:      conf.SwaggerEnabled,
+		MaxURLLength: conf.MaxURLLength,
+	}
+	if conf.AdminToken != "" {
+		caps.Auth = append(caps.Auth, "bearer")
+	}
+	for name := range features {
+		caps.Features[name] = featureEnabled(name)
+	}
+	if featureEnabled("html") {
+		caps.Formats = append(caps.Formats, echo.MIMETextHTML)
+	}
+	sort.Strings(caps.Formats)
+
+	return c.JSON(http.StatusOK, caps)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestCapabilities(t *testing.T) {
+	t.Setenv("DISABLED_FEATURES", "html,clone")
+	tc := newTestClient(t, func(conf *Config) {
+		conf.MaxPageLimit = 50
+		conf.DefaultPageLimit = 10
+		conf.AdminToken = "secret"
+		conf.StrictMode = true
+		conf.Gzip = true
+		conf.IdempotencyTTL = 0
+	})
+
+	rec := tc.Do(http.MethodGet, "/capabilities", "", "X-Tenant-ID", "acme")
+	expectStatus(t, rec, http.StatusOK)
+	caps := decode[Capabilities](t, rec)
+	if caps.Pagination.MaxLimit != 50 || caps.Pagination.DefaultLimit != 10 {
+		t.Errorf("pagination %+v", caps.Pagination)
+	}
+	if !slices.Equal(caps.Auth, []string{"bearer"}) || !caps.TenantHeader.Required || !caps.Gzip || caps.Idempotency {
+		t.Errorf("capabilities %s", rec.Body)
+	}
+	if caps.Features["html"] || caps.Features["clone"] || !caps.Features["reports"] {
+		t.Errorf("features %v", caps.Features)
+	}
+	if slices.Contains(caps.Formats, echo.MIMETextHTML) {
+		t.Errorf("formats %v list HTML with the html feature off", caps.Formats)
+	}
+	if caps.MaxURLLength != 2048 {
+		t.Errorf("max_url_length %d, want the default 2048", caps.MaxURLLength)
+	}
+}
+
+func TestCapabilitiesDefaults(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/capabilities", "")
+	expectStatus(t, rec, http.StatusOK)
+	caps := decode[Capabilities](t, rec)
+	if len(caps.Auth) != 0 || caps.TenantHeader.Required || !caps.Features["html"] || !slices.Contains(caps.Formats, echo.MIMETextHTML) {
+		t.Errorf("capabilities %s", rec.Body)
+	}
+}
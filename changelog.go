@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// changeActions are the accepted values of the action filter.
+var changeActions = map[string]bool{
+	store.ActionCreate: true,
+	store.ActionUpdate: true,
+	store.ActionDelete: true,
+}
+
+// GetChangelog godoc
+// @Summary      Changelog of all users
+// @Description  Lists the committed creates, updates and deletes of the tenant's users, oldest first, optionally only one action or a time range. Merges appear as an update of the target and a delete of the source.
+// @Description  Only the most recent 10000 changes are kept.
+// @Tags         changelog
+// @Produce      json
+// @Param        action  query     string  false  "Only this action"  Enums(create, update, delete)
+// @Param        since   query     string  false  "Only changes at or after this RFC3339 time"
+// @Param        until   query     string  false  "Only changes before this RFC3339 time"
+// @Param        page    query     int     false  "Page number (default 1)"
+// @Param        limit   query     int     false  "Page size (default DEFAULT_PAGE_LIMIT, max MAX_PAGE_LIMIT)"
+// @Success      200     {object}  Page[store.Change]
+// @Failure      400     {object}  map[string]string
+// @Router       /changelog [get]
+func GetChangelog(c echo.Context) error {
+	action := c.QueryParam("action")
+	if action != "" && !changeActions[action] {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "action must be one of: create, update, delete"})
+	}
+
+	var since, until time.Time
+	for name, t := range map[string]*time.Time{"since": &since, "until": &until} {
+		raw := c.QueryParam(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": name + " must be an RFC3339 timestamp"})
+		}
+		*t = parsed
+	}
+
+	page, limit, errs := parsePagination(c)
+	if len(errs) > 0 {
+		return paginationError(c, errs)
+	}
+
+	done := timeStore(c)
+	changes := usersFor(c).Changes()
+	done()
+
+	matched := []store.Change{}
+	for _, ch := range changes {
+		if action != "" && ch.Action != action {
+			continue
+		}
+		if ch.At.Before(since) || (!until.IsZero() && !ch.At.Before(until)) {
+			continue
+		}
+		matched = append(matched, ch)
+	}
+
+	result := paginate(matched, page, limit)
+	result.Filtered = action != "" || !since.IsZero() || !until.IsZero()
+	return c.JSON(http.StatusOK, result)
+}
+
+// mimeEventStream is the media type of server-sent events.
+const mimeEventStream = "text/event-stream"
+
+// tailKeepAlive is how often an idle changelog tail sends a comment, so
+// proxies do not close the connection.
+const tailKeepAlive = 15 * time.Second
+
+// TailChangelog godoc
+// @Summary      Live changelog
+// @Description  Streams the tenant's changes as server-sent events as they are committed: one "change" event per Change, with its seq as the event ID.
+// @Description  A client reconnecting with Last-Event-ID first gets the changes after that ID that are still held in the changelog (the most recent 10000), then live ones; older ones are gone. Without the header the stream starts with the next change.
+// @Tags         changelog
+// @Produce      text/event-stream
+// @Param        Last-Event-ID  header    int     false  "Seq of the last change received"
+// @Success      200            {string}  string  "Event stream"
+// @Failure      400            {object}  map[string]string
+// @Router       /changelog/tail [get]
+func TailChangelog(c echo.Context) error {
+	users := usersFor(c)
+
+	last := users.LastSeq()
+	if raw := c.Request().Header.Get("Last-Event-ID"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "Last-Event-ID must be a change seq"})
+		}
+		last = n
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, mimeEventStream)
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	keepAlive := time.NewTicker(tailKeepAlive)
+	defer keepAlive.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		changes, next := users.ChangesSince(last)
+		for _, ch := range changes {
+			data, err := json.Marshal(ch)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(res, "id: %d\nevent: change\ndata: %s\n\n", ch.Seq, data); err != nil {
+				return nil
+			}
+			last = ch.Seq
+		}
+		res.Flush()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-draining:
+			// the client reconnects with Last-Event-ID
+			return nil
+		case <-next:
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestChangelog(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{}
+	})
+
+	dewi := tc.CreateUser(store.User{Name: "Dewi", Age: 31})
+	eko := tc.CreateUser(store.User{Name: "Eko", Age: 40})
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `{"age":32}`), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodDelete, "/users/2", ""), http.StatusOK)
+
+	rec := tc.Do(http.MethodGet, "/changelog", "")
+	expectStatus(t, rec, http.StatusOK)
+	all := decode[Page[store.Change]](t, rec)
+	var got []string
+	for i, ch := range all.Data {
+		if ch.Seq != i+1 || (i > 0 && ch.At.Before(all.Data[i-1].At)) {
+			t.Errorf("change %d out of order: %+v", i, ch)
+		}
+		got = append(got, ch.Action)
+	}
+	if want := []string{"create", "create", "update", "delete"}; !slices.Equal(got, want) || all.Filtered {
+		t.Fatalf("actions %v, filtered %v; want %v, false", got, all.Filtered, want)
+	}
+	if all.Data[0].UserID != dewi.ID || all.Data[3].UserID != eko.ID {
+		t.Errorf("user IDs %+v", all.Data)
+	}
+
+	rec = tc.Do(http.MethodGet, "/changelog?action=create", "")
+	expectStatus(t, rec, http.StatusOK)
+	if page := decode[Page[store.Change]](t, rec); len(page.Data) != 2 || !page.Filtered || page.Data[1].UserID != eko.ID {
+		t.Errorf("action=create: %s", rec.Body)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/changelog?action=merge", ""), http.StatusBadRequest)
+
+	// since is inclusive, until exclusive
+	at := all.Data[2].At.Format("2006-01-02T15:04:05.999999999Z07:00")
+	rec = tc.Do(http.MethodGet, "/changelog?since="+at, "")
+	if page := decode[Page[store.Change]](t, rec); len(page.Data) == 0 || page.Data[0].Seq > 3 {
+		t.Errorf("since %s: %s", at, rec.Body)
+	}
+	rec = tc.Do(http.MethodGet, "/changelog?until="+at, "")
+	if page := decode[Page[store.Change]](t, rec); len(page.Data) > 2 {
+		t.Errorf("until %s: %s", at, rec.Body)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/changelog?since=yesterday", ""), http.StatusBadRequest)
+
+	rec = tc.Do(http.MethodGet, "/changelog?page=2&limit=3", "")
+	expectStatus(t, rec, http.StatusOK)
+	if page := decode[Page[store.Change]](t, rec); len(page.Data) != 1 || page.Data[0].Seq != 4 || page.Total != 4 || page.TotalPages != 2 {
+		t.Errorf("page 2: %s", rec.Body)
+	}
+}
+
+func TestTailChangelog(t *testing.T) {
+	tc := newTestClient(t, nil)
+	base := listen(t, tc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, base+"/changelog/tail", nil)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK || res.Header.Get(echo.HeaderContentType) != mimeEventStream {
+		t.Fatalf("status %d, Content-Type %q", res.StatusCode, res.Header.Get(echo.HeaderContentType))
+	}
+
+	// subscribed once the headers are in; this change comes after
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/2", `{"age":26}`), http.StatusOK)
+
+	event := readEvent(t, bufio.NewScanner(res.Body))
+	if event["event"] != "change" || event["id"] != "1" {
+		t.Fatalf("event %v", event)
+	}
+	var ch store.Change
+	if err := json.Unmarshal([]byte(event["data"]), &ch); err != nil || ch.Action != store.ActionUpdate || ch.UserID != 2 {
+		t.Errorf("data %q: %+v, %v", event["data"], ch, err)
+	}
+}
+
+func TestTailChangelogReplay(t *testing.T) {
+	tc := newTestClient(t, nil)
+	tc.CreateUser(store.User{Name: "Dewi", Age: 31})
+	expectStatus(t, tc.Do(http.MethodDelete, "/users/1", ""), http.StatusOK)
+
+	// a client gone after the replay ends the stream
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/changelog/tail", nil).WithContext(ctx)
+	req.Header.Set("Last-Event-ID", "1")
+	rec := httptest.NewRecorder()
+	tc.e.ServeHTTP(rec, req)
+
+	event := readEvent(t, bufio.NewScanner(rec.Body))
+	if event["id"] != "2" || !strings.Contains(event["data"], `"action":"delete"`) {
+		t.Errorf("replayed %v, want change 2, the delete", event)
+	}
+	if strings.Contains(rec.Body.String(), "id: 1\n") {
+		t.Errorf("replayed the change the client had: %s", rec.Body)
+	}
+
+	expectStatus(t, tc.Do(http.MethodGet, "/changelog/tail", "", "Last-Event-ID", "x"), http.StatusBadRequest)
+}
+
+// readEvent reads one server-sent event, skipping comments, as field: value
+// pairs.
+func readEvent(t *testing.T, lines *bufio.Scanner) map[string]string {
+	t.Helper()
+	event := map[string]string{}
+	for lines.Scan() {
+		line := lines.Text()
+		if line == "" && len(event) > 0 {
+			return event
+		}
+		if name, value, ok := strings.Cut(line, ": "); ok && name != "" {
+			event[name] = value
+		}
+	}
+	t.Fatalf("stream ended before an event: %v", lines.Err())
+	return nil
+}
+
+func TestTailChangelogWithProblemJSON(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.ProblemJSON = true
+	})
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/2", `{"age":26}`), http.StatusOK)
+
+	// no SSE Accept: the stream is still not buffered, so flushing works
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/changelog/tail", nil).WithContext(ctx)
+	req.Header.Set("Last-Event-ID", "0")
+	rec := httptest.NewRecorder()
+	tc.e.ServeHTTP(rec, req)
+
+	expectStatus(t, rec, http.StatusOK)
+	if !rec.Flushed || rec.Header().Get(echo.HeaderContentType) != mimeEventStream {
+		t.Errorf("flushed %v, Content-Type %q", rec.Flushed, rec.Header().Get(echo.HeaderContentType))
+	}
+	if event := readEvent(t, bufio.NewScanner(rec.Body)); event["id"] != "1" {
+		t.Errorf("event %v, want change 1", event)
+	}
+}
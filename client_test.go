@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"go-echo/internal/apitest"
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// testClient drives the app in-process through an apitest.Client, with
+// helpers that know the users API.
+//
+// The configuration and the tenants' stores are package state, set up by
+// newServer, so only the client built last is live: a test needing several
+// setups uses them one after the other, and Do fails the test on a client
+// that a newer one has replaced, rather than have it silently act on the
+// newer client's state.
+type testClient struct {
+	*apitest.Client
+	t *testing.T
+	e *echo.Echo
+	// generation is liveGeneration when the client was built.
+	generation int
+}
+
+// liveGeneration counts the test clients built; the latest is live.
+var liveGeneration int
+
+// newTestClient builds the app from the default configuration, as read
+// from an empty environment, after configure has adjusted it. configure
+// may be nil; setting conf.Seed picks the users the store starts with.
+func newTestClient(t *testing.T, configure func(conf *Config)) *testClient {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	conf, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if configure != nil {
+		configure(conf)
+	}
+	e := newServer(conf)
+	liveGeneration++
+	return &testClient{Client: apitest.New(t, e), t: t, e: e, generation: liveGeneration}
+}
+
+// Do is apitest.Client.Do on a live client.
+func (tc *testClient) Do(method, target, body string, header ...string) *httptest.ResponseRecorder {
+	tc.t.Helper()
+	if tc.generation != liveGeneration {
+		tc.t.Fatalf("%s %s on a test client replaced by a newer one", method, target)
+	}
+	return tc.Client.Do(method, target, body, header...)
+}
+
+// CreateUser creates u and returns the stored user, failing the test on
+// anything but 201.
+func (tc *testClient) CreateUser(u store.User) store.User {
+	tc.t.Helper()
+	body, _ := json.Marshal(u)
+	rec := tc.Do(http.MethodPost, "/users", string(body))
+	if rec.Code != http.StatusCreated {
+		tc.t.Fatalf("POST /users: status %d, body %s", rec.Code, rec.Body)
+	}
+	return decode[store.User](tc.t, rec)
+}
+
+// GetUser returns the user with the given ID, failing the test on anything
+// but 200.
+func (tc *testClient) GetUser(id int) store.User {
+	tc.t.Helper()
+	rec := tc.Do(http.MethodGet, "/users/"+strconv.Itoa(id), "")
+	if rec.Code != http.StatusOK {
+		tc.t.Fatalf("GET /users/%d: status %d, body %s", id, rec.Code, rec.Body)
+	}
+	return decode[store.User](tc.t, rec)
+}
+
+// decode is apitest.Decode.
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	return apitest.Decode[T](t, rec)
+}
+
+// expectStatus is apitest.ExpectStatus.
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	apitest.ExpectStatus(t, rec, status)
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{}
+	})
+
+	created := tc.CreateUser(store.User{Name: "Dewi", Age: 31})
+	if created.ID != 1 {
+		t.Errorf("created ID %d, want 1", created.ID)
+	}
+
+	got := tc.GetUser(created.ID)
+	if got.Name != "Dewi" || got.Age != 31 {
+		t.Errorf("GetUser = %+v, want Dewi, 31", got)
+	}
+
+	expectStatus(t, tc.Do(http.MethodGet, "/users/2", ""), http.StatusNotFound)
+}
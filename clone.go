@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxCloneSuffix bounds the numbered copy names tried for a clone.
+const maxCloneSuffix = 100
+
+// maxNameLength is the max rule on store.User.Name, in characters.
+const maxNameLength = 100
+
+// cloneName is the name of the n-th copy of name: "Agus (copy)", then
+// "Agus (copy 2)" and so on. name is cut short where the suffix would push
+// the result past maxNameLength.
+func cloneName(name string, n int) string {
+	suffix := " (copy)"
+	if n > 1 {
+		suffix = fmt.Sprintf(" (copy %d)", n)
+	}
+	if base := []rune(name); len(base)+len(suffix) > maxNameLength {
+		name = strings.TrimRight(string(base[:maxNameLength-len(suffix)]), " ")
+	}
+	return name + suffix
+}
+
+// CloneUser godoc
+// @Summary      Duplicate a user
+// @Description  Creates a new user with the source's name suffixed with " (copy)" and its age. If that name is taken, " (copy 2)", " (copy 3)" and so on are tried.
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int                true  "Source user ID"
+// @Success      201  {object}  store.User
+// @Header       201  {string}  Location           "URL of the created user"
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  NotFoundResponse
+// @Failure      409  {object}  map[string]string
+// @Failure      422  {object}  map[string]string
+// @Failure      507  {object}  map[string]string  "MAX_USERS reached"
+// @Router       /users/{id}/clone [post]
+func CloneUser(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid user ID"})
+	}
+
+	users := usersFor(c)
+	done := timeStore(c)
+	defer done()
+
+	source, err := users.Get(id)
+	if err != nil {
+		return storeError(c, err)
+	}
+
+	for n := 1; n <= maxCloneSuffix; n++ {
+		clone := store.User{Name: cloneName(source.Name, n), Age: source.Age}
+		if err := validateRequest(c, &clone); err != nil {
+			// only a taken name: try the next suffix
+			if validationStatus(err) == http.StatusConflict {
+				continue
+			}
+			return validationError(c, err)
+		}
+
+		created, err := users.Create(clone, false)
+		if errors.Is(err, store.ErrDuplicateName) {
+			continue
+		}
+		if err != nil {
+			return storeError(c, err)
+		}
+
+		c.Response().Header().Set(echo.HeaderLocation, c.Echo().Reverse(routeGetUser, created.ID))
+		return c.JSON(http.StatusCreated, created)
+	}
+	return c.JSON(http.StatusConflict, echo.Map{"error": "no free copy name"})
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"go-echo/store"
+)
+
+func TestCloneLongName(t *testing.T) {
+	tc := newTestClient(t, nil)
+	long := tc.CreateUser(store.User{Name: strings.Repeat("é", 100), Age: 40})
+
+	for range 2 {
+		rec := tc.Do(http.MethodPost, "/users/"+strconv.Itoa(long.ID)+"/clone", "")
+		expectStatus(t, rec, http.StatusCreated)
+		clone := decode[store.User](t, rec)
+		if n := utf8.RuneCountInString(clone.Name); n > 100 || !strings.Contains(clone.Name, "(copy") {
+			t.Errorf("clone name %q, %d characters", clone.Name, n)
+		}
+	}
+}
+
+func TestCloneUser(t *testing.T) {
+	tc := newTestClient(t, nil)
+	source := tc.GetUser(2)
+
+	rec := tc.Do(http.MethodPost, "/users/2/clone", "")
+	expectStatus(t, rec, http.StatusCreated)
+	clone := decode[store.User](t, rec)
+	if clone.ID != 4 || clone.Name != "Bagus (copy)" || clone.Age != source.Age {
+		t.Errorf("clone %+v", clone)
+	}
+	if !clone.CreatedAt.After(source.CreatedAt) || rec.Header().Get("Location") != "/users/4" {
+		t.Errorf("clone created %v, Location %q", clone.CreatedAt, rec.Header().Get("Location"))
+	}
+
+	// the next copy takes the next free suffix
+	rec = tc.Do(http.MethodPost, "/users/2/clone", "")
+	expectStatus(t, rec, http.StatusCreated)
+	if got := decode[store.User](t, rec).Name; got != "Bagus (copy 2)" {
+		t.Errorf("second clone %q", got)
+	}
+
+	expectStatus(t, tc.Do(http.MethodPost, "/users/42/clone", ""), http.StatusNotFound)
+}
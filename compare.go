@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UserComparison puts two users side by side with, per editable field,
+// whether their values are "same" or "different".
+type UserComparison struct {
+	A    store.User        `json:"a"`
+	B    store.User        `json:"b"`
+	Diff map[string]string `json:"diff" example:"name:different,age:same"`
+}
+
+// CompareUsers godoc
+// @Summary      Compare two users
+// @Description  Returns users a and b side by side with a per-field diff of name and age, e.g. to review a merge
+// @Tags         users
+// @Produce      json
+// @Param        a    query     int  true  "First user ID"
+// @Param        b    query     int  true  "Second user ID"
+// @Success      200  {object}  UserComparison
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  NotFoundResponse
+// @Router       /users/compare [get]
+func CompareUsers(c echo.Context) error {
+	a, errA := strconv.Atoi(c.QueryParam("a"))
+	b, errB := strconv.Atoi(c.QueryParam("b"))
+	if errA != nil || errB != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "a and b must be user IDs"})
+	}
+	if a == b {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Cannot compare a user with itself"})
+	}
+
+	users := usersFor(c)
+	done := timeStore(c)
+	userA, errA := users.Get(a)
+	userB, errB := users.Get(b)
+	done()
+	if errA != nil {
+		return storeError(c, errA)
+	}
+	if errB != nil {
+		return storeError(c, errB)
+	}
+
+	return c.JSON(http.StatusOK, UserComparison{
+		A: userA,
+		B: userB,
+		Diff: map[string]string{
+			"name": sameOrDifferent(userA.Name == userB.Name),
+			"age":  sameOrDifferent(userA.Age == userB.Age),
+		},
+	})
+}
+
+func sameOrDifferent(same bool) string {
+	if same {
+		return "same"
+	}
+	return "different"
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"maps"
+	"net/http"
+	"testing"
+
+	"go-echo/store"
+)
+
+func TestCompareUsers(t *testing.T) {
+	// legacy data from before names had to be unique
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{
+			{ID: 1, Name: "Agus", Age: 15},
+			{ID: 2, Name: "Agus", Age: 15},
+			{ID: 3, Name: "Caca", Age: 15},
+		}
+	})
+	diff := func(a, b string) map[string]string {
+		t.Helper()
+		rec := tc.Do(http.MethodGet, "/users/compare?a="+a+"&b="+b, "")
+		expectStatus(t, rec, http.StatusOK)
+		cmp := decode[UserComparison](t, rec)
+		if a == "1" && (cmp.A.ID != 1 || cmp.B.Name == "") {
+			t.Errorf("side by side %+v", cmp)
+		}
+		return cmp.Diff
+	}
+
+	if got := diff("1", "3"); !maps.Equal(got, map[string]string{"name": "different", "age": "same"}) {
+		t.Errorf("1 vs 3: %v", got)
+	}
+	if got := diff("1", "2"); !maps.Equal(got, map[string]string{"name": "same", "age": "same"}) {
+		t.Errorf("1 vs 2: %v", got)
+	}
+
+	expectStatus(t, tc.Do(http.MethodGet, "/users/compare?a=1&b=42", ""), http.StatusNotFound)
+	expectStatus(t, tc.Do(http.MethodGet, "/users/compare?a=42&b=1", ""), http.StatusNotFound)
+	expectStatus(t, tc.Do(http.MethodGet, "/users/compare?a=1&b=1", ""), http.StatusBadRequest)
+	expectStatus(t, tc.Do(http.MethodGet, "/users/compare?a=1&b=x", ""), http.StatusBadRequest)
+	expectStatus(t, tc.Do(http.MethodGet, "/users/compare?a=1", ""), http.StatusBadRequest)
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go-echo/store"
+
+	"github.com/labstack/gommon/log"
+)
+
+// Config holds the settings read from the environment. When CONFIG_FILE
+// names a file of KEY=VALUE lines, its entries take precedence over the
+// process environment; that file is what makes reloading useful, since a
+// running process cannot see changes to its own environment.
+//
+// Hot-reloadable through POST /admin/reload: LOG_LEVEL, DEFAULT_PAGE_LIMIT
+// and MAX_PAGE_LIMIT. Everything else is only read at startup.
+type Config struct {
+	// LogLevel is one of debug, info, warn, error or off (LOG_LEVEL). The
+	// default, warn, shows the slow request and retry storm warnings.
+	LogLevel string `json:"log_level"`
+	// DefaultPageLimit is the page size used when limit is not given
+	// (DEFAULT_PAGE_LIMIT).
+	DefaultPageLimit int `json:"default_page_limit"`
+	// MaxPageLimit is the largest accepted limit (MAX_PAGE_LIMIT).
+	MaxPageLimit int `json:"max_page_limit"`
+	// DefaultSort is the order GET /users uses when no sort is given
+	// (DEFAULT_SORT), in the same syntax as the sort parameter, e.g. "-id"
+	// for newest first.
+	DefaultSort string `json:"default_sort"`
+
+	// Env is the deployment environment, "development" or "production"
+	// (APP_ENV). It selects defaults for other settings.
+	Env string `json:"env"`
+	// SwaggerEnabled registers the /swagger UI and the raw spec at
+	// /openapi.json and /openapi.yaml (SWAGGER_ENABLED). Defaults to true, or
+	// false when Env is production.
+	SwaggerEnabled bool `json:"swagger_enabled"`
+
+	// JSONEscapeHTML escapes <, > and & in JSON responses
+	// (JSON_ESCAPE_HTML). Defaults to true; only turn it off when responses
+	// are never embedded in HTML unescaped.
+	JSONEscapeHTML bool `json:"json_escape_html"`
+
+	// MaxURLLength is the longest request URL, path plus query, accepted
+	// before answering 414 (MAX_URL_LENGTH). Zero turns the check off.
+	MaxURLLength int `json:"max_url_length"`
+
+	// MaxUsers caps the number of stored users, across all tenants
+	// (MAX_USERS); creating past it returns 507. Zero means unlimited.
+	MaxUsers int `json:"max_users"`
+	// MaxTenants caps the tenants created from the tenant header, besides
+	// the default one (MAX_TENANTS). A tenant is created by its first user;
+	// past the cap that create gets 507, while reads of an unknown tenant
+	// see no users. Zero means unlimited.
+	MaxTenants int `json:"max_tenants"`
+
+	// DisplayNames adds a title-cased display_name to every user
+	// (DISPLAY_NAMES). Off by default; names are stored as entered either
+	// way.
+	DisplayNames bool `json:"display_names"`
+	// DisplayNameParticles are the words kept lower-case inside a display
+	// name (DISPLAY_NAME_PARTICLES, comma-separated), e.g. de, van.
+	DisplayNameParticles []string `json:"display_name_particles"`
+
+	// AgeWarnAbove is the age above which a write succeeds with a warning
+	// in the response (AGE_WARN_ABOVE). Zero turns the warning off.
+	AgeWarnAbove int `json:"age_warn_above"`
+
+	// CORSAllowOrigins lists the origins allowed to call the API
+	// (CORS_ALLOW_ORIGINS, comma-separated). CORS is off when empty.
+	CORSAllowOrigins []string `json:"cors_allow_origins"`
+	// CORSAllowCredentials sends Access-Control-Allow-Credentials
+	// (CORS_ALLOW_CREDENTIALS). Only allowed with explicit origins.
+	CORSAllowCredentials bool `json:"cors_allow_credentials"`
+	// CORSMaxAge is how long, in seconds, browsers may cache a preflight
+	// (CORS_MAX_AGE).
+	CORSMaxAge int `json:"cors_max_age"`
+
+	// LogBodies turns on request/response body logging for the API routes
+	// (LOG_BODIES). Off by default.
+	LogBodies bool `json:"log_bodies"`
+	// LogBodyMaxBytes caps how much of each logged body is kept
+	// (LOG_BODY_MAX_BYTES).
+	LogBodyMaxBytes int `json:"log_body_max_bytes"`
+
+	// ForceHTTPS redirects or rejects plaintext requests and sends HSTS
+	// (FORCE_HTTPS). Off by default; only enable it behind a proxy that sets
+	// X-Forwarded-Proto.
+	ForceHTTPS bool `json:"force_https"`
+	// HSTSMaxAge is the Strict-Transport-Security max-age in seconds
+	// (HSTS_MAX_AGE).
+	HSTSMaxAge int `json:"hsts_max_age"`
+
+	// Gzip compresses responses for clients that accept it (GZIP). Off by
+	// default.
+	Gzip bool `json:"gzip"`
+
+	// SlowRequestThreshold is the latency above which a request is logged
+	// at WARN (SLOW_REQUEST_THRESHOLD, a Go duration such as 500ms), which
+	// the default LOG_LEVEL shows. Zero turns the log off. JSON shows it in
+	// nanoseconds.
+	SlowRequestThreshold time.Duration `json:"slow_request_threshold" swaggertype:"integer" example:"500000000"`
+
+	// RetryStormThreshold is how many identical requests (method, URL and
+	// body) from one client within RetryStormWindow get logged at WARN as a
+	// likely retry loop (RETRY_STORM_THRESHOLD). Zero, the default, turns
+	// the log off. The warnings need LOG_LEVEL warn or lower, the default.
+	RetryStormThreshold int `json:"retry_storm_threshold"`
+	// RetryStormWindow is the window RetryStormThreshold counts in
+	// (RETRY_STORM_WINDOW, a Go duration). JSON shows it in nanoseconds.
+	RetryStormWindow time.Duration `json:"retry_storm_window" swaggertype:"integer" example:"10000000000"`
+
+	// HealthStoreThreshold is the store latency above which
+	// /healthz/detailed reports degraded (HEALTH_STORE_THRESHOLD, a Go
+	// duration). JSON shows it in nanoseconds.
+	HealthStoreThreshold time.Duration `json:"health_store_threshold" swaggertype:"integer" example:"100000000"`
+
+	// H2C also serves HTTP/2 over cleartext connections (H2C), for internal
+	// callers that multiplex without TLS. Off by default.
+	H2C bool `json:"h2c"`
+
+	// ShutdownTimeout is how long in-flight requests get to finish once
+	// SIGINT or SIGTERM arrives (SHUTDOWN_TIMEOUT, a Go duration); the
+	// connections of those still running then are closed. Keep it below
+	// the deployment's grace period. JSON shows it in nanoseconds.
+	ShutdownTimeout time.Duration `json:"shutdown_timeout" swaggertype:"integer" example:"10000000000"`
+
+	// ServerTiming adds a Server-Timing header with handler and store
+	// durations (SERVER_TIMING). Off by default.
+	ServerTiming bool `json:"server_timing"`
+
+	// IdempotencyTTL is how long a write response is kept for replay to
+	// retries with the same Idempotency-Key (IDEMPOTENCY_TTL, a Go
+	// duration). Zero turns the Idempotency-Key handling off. JSON shows it
+	// in nanoseconds.
+	IdempotencyTTL time.Duration `json:"idempotency_ttl" swaggertype:"integer" example:"86400000000000"`
+	// IdempotencyMaxKeys caps the stored responses (IDEMPOTENCY_MAX_KEYS);
+	// past it the ones closest to expiry are dropped early.
+	IdempotencyMaxKeys int `json:"idempotency_max_keys"`
+
+	// RequestIDHeader is the header RequestID reads and echoes
+	// (REQUEST_ID_HEADER), e.g. X-Correlation-ID.
+	RequestIDHeader string `json:"request_id_header"`
+
+	// StrictMode rejects API requests without RequiredHeader with 400
+	// (STRICT_MODE). Off by default.
+	StrictMode bool `json:"strict_mode"`
+	// RequiredHeader is the header strict mode insists on
+	// (REQUIRED_HEADER), injected by the gateway. Its value names the tenant
+	// whose users a request sees.
+	RequiredHeader string `json:"required_header"`
+
+	// ProblemJSON renders every JSON error as application/problem+json
+	// (RFC 7807) rather than only for clients asking for it in Accept
+	// (PROBLEM_JSON). Off by default.
+	ProblemJSON bool `json:"problem_json"`
+
+	// DisabledFeatures switches optional endpoints off
+	// (DISABLED_FEATURES, comma-separated); their routes answer 404. The
+	// flag names are html, bulk, merge, clone, similar, schema, next_id and
+	// reports, see features for the routes each covers.
+	DisabledFeatures []string `json:"disabled_features"`
+
+	// Seed is the dataset the default tenant starts with, read from the
+	// JSON file named by SEED_FILE. Nil means the built-in three users.
+	Seed []store.User `json:"-"`
+
+	// AdminToken is the bearer token for /admin routes (ADMIN_TOKEN). The
+	// admin routes are not registered when it is empty.
+	AdminToken string `json:"-"`
+}
+
+var logLevels = map[string]log.Lvl{
+	"debug": log.DEBUG,
+	"info":  log.INFO,
+	"warn":  log.WARN,
+	"error": log.ERROR,
+	"off":   log.OFF,
+}
+
+// config is the effective configuration, swapped atomically on reload.
+var config atomic.Pointer[Config]
+
+// cfg returns the effective configuration.
+func cfg() *Config {
+	return config.Load()
+}
+
+func init() {
+	config.Store(&Config{
+		Env:              "development",
+		SwaggerEnabled:   true,
+		LogLevel:         "warn",
+		DefaultPageLimit: 20,
+		MaxPageLimit:     100,
+		DefaultSort:      "id",
+		RequiredHeader:   "X-Tenant-ID",
+		IdempotencyTTL:   24 * time.Hour,
+		RequestIDHeader:  "X-Request-ID",
+		AgeWarnAbove:     120,
+		MaxURLLength:     2048,
+		MaxTenants:       100,
+		RetryStormWindow: 10 * time.Second,
+		ShutdownTimeout:  10 * time.Second,
+
+		IdempotencyMaxKeys:   10000,
+		DisplayNameParticles: defaultNameParticles,
+	})
+}
+
+// loadConfig reads the configuration from CONFIG_FILE and the environment
+// and validates it.
+func loadConfig() (*Config, error) {
+	env, err := readEnv(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{
+		Env:        strings.ToLower(env.String("APP_ENV", "development")),
+		LogLevel:   strings.ToLower(env.String("LOG_LEVEL", "warn")),
+		AdminToken: env.String("ADMIN_TOKEN", ""),
+	}
+	if c.Env != "development" && c.Env != "production" {
+		return nil, fmt.Errorf("APP_ENV: must be development or production, got %q", c.Env)
+	}
+
+	if c.SwaggerEnabled, err = env.Bool("SWAGGER_ENABLED", c.Env != "production"); err != nil {
+		return nil, err
+	}
+
+	if c.JSONEscapeHTML, err = env.Bool("JSON_ESCAPE_HTML", true); err != nil {
+		return nil, err
+	}
+
+	if c.MaxURLLength, err = env.Int("MAX_URL_LENGTH", 2048); err != nil {
+		return nil, err
+	}
+	if c.MaxURLLength < 0 {
+		return nil, fmt.Errorf("MAX_URL_LENGTH: must not be negative")
+	}
+
+	if c.MaxUsers, err = env.Int("MAX_USERS", 0); err != nil {
+		return nil, err
+	}
+	if c.MaxUsers < 0 {
+		return nil, fmt.Errorf("MAX_USERS: must not be negative")
+	}
+	if c.MaxTenants, err = env.Int("MAX_TENANTS", 100); err != nil {
+		return nil, err
+	}
+	if c.MaxTenants < 0 {
+		return nil, fmt.Errorf("MAX_TENANTS: must not be negative")
+	}
+
+	if c.DisplayNames, err = env.Bool("DISPLAY_NAMES", false); err != nil {
+		return nil, err
+	}
+	if c.DisplayNameParticles = env.List("DISPLAY_NAME_PARTICLES"); c.DisplayNameParticles == nil {
+		c.DisplayNameParticles = defaultNameParticles
+	}
+
+	if c.AgeWarnAbove, err = env.Int("AGE_WARN_ABOVE", 120); err != nil {
+		return nil, err
+	}
+	if c.AgeWarnAbove < 0 {
+		return nil, fmt.Errorf("AGE_WARN_ABOVE: must not be negative")
+	}
+
+	c.CORSAllowOrigins = env.List("CORS_ALLOW_ORIGINS")
+	if c.CORSAllowCredentials, err = env.Bool("CORS_ALLOW_CREDENTIALS", false); err != nil {
+		return nil, err
+	}
+	if c.CORSMaxAge, err = env.Int("CORS_MAX_AGE", 600); err != nil {
+		return nil, err
+	}
+	if c.CORSMaxAge < 0 {
+		return nil, fmt.Errorf("CORS_MAX_AGE: must not be negative")
+	}
+	if c.CORSAllowCredentials {
+		for _, origin := range c.CORSAllowOrigins {
+			if origin == "*" {
+				return nil, fmt.Errorf("CORS_ALLOW_CREDENTIALS: cannot be combined with a wildcard origin")
+			}
+		}
+	}
+
+	if c.LogBodies, err = env.Bool("LOG_BODIES", false); err != nil {
+		return nil, err
+	}
+	if c.LogBodyMaxBytes, err = env.Int("LOG_BODY_MAX_BYTES", 2048); err != nil {
+		return nil, err
+	}
+	if c.LogBodyMaxBytes < 1 {
+		return nil, fmt.Errorf("LOG_BODY_MAX_BYTES: must be at least 1")
+	}
+
+	if c.Gzip, err = env.Bool("GZIP", false); err != nil {
+		return nil, err
+	}
+
+	if c.SlowRequestThreshold, err = env.Duration("SLOW_REQUEST_THRESHOLD", 500*time.Millisecond); err != nil {
+		return nil, err
+	}
+	if c.SlowRequestThreshold < 0 {
+		return nil, fmt.Errorf("SLOW_REQUEST_THRESHOLD: must not be negative")
+	}
+
+	if c.RetryStormThreshold, err = env.Int("RETRY_STORM_THRESHOLD", 0); err != nil {
+		return nil, err
+	}
+	if c.RetryStormThreshold < 0 {
+		return nil, fmt.Errorf("RETRY_STORM_THRESHOLD: must not be negative")
+	}
+	if c.RetryStormWindow, err = env.Duration("RETRY_STORM_WINDOW", 10*time.Second); err != nil {
+		return nil, err
+	}
+	if c.RetryStormWindow <= 0 {
+		return nil, fmt.Errorf("RETRY_STORM_WINDOW: must be positive")
+	}
+
+	if c.HealthStoreThreshold, err = env.Duration("HEALTH_STORE_THRESHOLD", defaultHealthStoreThreshold); err != nil {
+		return nil, err
+	}
+	if c.HealthStoreThreshold <= 0 {
+		return nil, fmt.Errorf("HEALTH_STORE_THRESHOLD: must be positive")
+	}
+
+	if c.H2C, err = env.Bool("H2C", false); err != nil {
+		return nil, err
+	}
+
+	if c.ShutdownTimeout, err = env.Duration("SHUTDOWN_TIMEOUT", 10*time.Second); err != nil {
+		return nil, err
+	}
+	if c.ShutdownTimeout < 0 {
+		return nil, fmt.Errorf("SHUTDOWN_TIMEOUT: must not be negative")
+	}
+
+	if c.ServerTiming, err = env.Bool("SERVER_TIMING", false); err != nil {
+		return nil, err
+	}
+
+	if c.ProblemJSON, err = env.Bool("PROBLEM_JSON", false); err != nil {
+		return nil, err
+	}
+
+	if c.IdempotencyTTL, err = env.Duration("IDEMPOTENCY_TTL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if c.IdempotencyTTL < 0 {
+		return nil, fmt.Errorf("IDEMPOTENCY_TTL: must not be negative")
+	}
+	if c.IdempotencyMaxKeys, err = env.Int("IDEMPOTENCY_MAX_KEYS", 10000); err != nil {
+		return nil, err
+	}
+	if c.IdempotencyMaxKeys < 1 {
+		return nil, fmt.Errorf("IDEMPOTENCY_MAX_KEYS: must be at least 1")
+	}
+
+	c.RequestIDHeader = env.String("REQUEST_ID_HEADER", "X-Request-ID")
+
+	if c.StrictMode, err = env.Bool("STRICT_MODE", false); err != nil {
+		return nil, err
+	}
+	c.RequiredHeader = env.String("REQUIRED_HEADER", "X-Tenant-ID")
+
+	if c.ForceHTTPS, err = env.Bool("FORCE_HTTPS", false); err != nil {
+		return nil, err
+	}
+	if c.HSTSMaxAge, err = env.Int("HSTS_MAX_AGE", 31536000); err != nil {
+		return nil, err
+	}
+	if c.HSTSMaxAge < 0 {
+		return nil, fmt.Errorf("HSTS_MAX_AGE: must not be negative")
+	}
+
+	if c.DefaultPageLimit, err = env.Int("DEFAULT_PAGE_LIMIT", 20); err != nil {
+		return nil, err
+	}
+	if c.MaxPageLimit, err = env.Int("MAX_PAGE_LIMIT", 100); err != nil {
+		return nil, err
+	}
+
+	c.DefaultSort = env.String("DEFAULT_SORT", "id")
+	if _, err := parseSort(c.DefaultSort); err != nil {
+		return nil, fmt.Errorf("DEFAULT_SORT: %w", err)
+	}
+
+	if path := env.String("SEED_FILE", ""); path != "" {
+		if c.Seed, err = loadSeed(path, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+	}
+
+	c.DisabledFeatures = env.List("DISABLED_FEATURES")
+	if err := checkFeatures(c.DisabledFeatures); err != nil {
+		return nil, err
+	}
+
+	if _, ok := logLevels[c.LogLevel]; !ok {
+		return nil, fmt.Errorf("LOG_LEVEL: unknown level %q", c.LogLevel)
+	}
+	if c.MaxPageLimit < 1 {
+		return nil, fmt.Errorf("MAX_PAGE_LIMIT: must be at least 1")
+	}
+	if c.DefaultPageLimit < 1 || c.DefaultPageLimit > c.MaxPageLimit {
+		return nil, fmt.Errorf("DEFAULT_PAGE_LIMIT: must be between 1 and MAX_PAGE_LIMIT")
+	}
+	return c, nil
+}
+
+// envSource resolves configuration keys, preferring the entries read from
+// CONFIG_FILE over the process environment.
+type envSource map[string]string
+
+// readEnv parses the KEY=VALUE file at path. Blank lines and lines starting
+// with # are skipped. An empty path yields an empty source.
+func readEnv(path string) (envSource, error) {
+	env := envSource{}
+	if path == "" {
+		return env, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("CONFIG_FILE: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("CONFIG_FILE: line %d: expected KEY=VALUE", line)
+		}
+		env[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return env, scanner.Err()
+}
+
+func (env envSource) lookup(key string) string {
+	if v, ok := env[key]; ok {
+		return v
+	}
+	return os.Getenv(key)
+}
+
+func (env envSource) String(key, fallback string) string {
+	if v := env.lookup(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// List splits a comma-separated value, dropping empty entries.
+func (env envSource) List(key string) []string {
+	var items []string
+	for _, item := range strings.Split(env.lookup(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (env envSource) Int(key string, fallback int) (int, error) {
+	v := env.lookup(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not an integer", key, v)
+	}
+	return n, nil
+}
+
+func (env envSource) Bool(key string, fallback bool) (bool, error) {
+	v := env.lookup(key)
+	if v == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s: %q is not a boolean", key, v)
+	}
+	return b, nil
+}
+
+func (env envSource) Duration(key string, fallback time.Duration) (time.Duration, error) {
+	v := env.lookup(key)
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a duration", key, v)
+	}
+	return d, nil
+}
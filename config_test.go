@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestLoadConfigRejectsBadLogBodyMaxBytes(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	for _, v := range []string{"0", "-1"} {
+		t.Setenv("LOG_BODY_MAX_BYTES", v)
+		if _, err := loadConfig(); err == nil {
+			t.Errorf("LOG_BODY_MAX_BYTES=%s accepted", v)
+		}
+	}
+}
+
+func TestLoadConfigRejectsBadCORSMaxAge(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	for _, v := range []string{"-1", "ten"} {
+		t.Setenv("CORS_MAX_AGE", v)
+		if _, err := loadConfig(); err == nil {
+			t.Errorf("CORS_MAX_AGE=%s accepted", v)
+		}
+	}
+	t.Setenv("CORS_MAX_AGE", "0")
+	if _, err := loadConfig(); err != nil {
+		t.Errorf("CORS_MAX_AGE=0: %v", err)
+	}
+}
+
+func TestLoadConfigRejectsBadHealthStoreThreshold(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	for _, v := range []string{"0", "-1s", "fast"} {
+		t.Setenv("HEALTH_STORE_THRESHOLD", v)
+		if _, err := loadConfig(); err == nil {
+			t.Errorf("HEALTH_STORE_THRESHOLD=%s accepted", v)
+		}
+	}
+}
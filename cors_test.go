@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCORSCredentialsAndMaxAge(t *testing.T) {
+	t.Setenv("CORS_ALLOW_ORIGINS", "https://app.example.com")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	t.Setenv("CORS_MAX_AGE", "900")
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodOptions, "/users", "",
+		"Origin", "https://app.example.com",
+		"Access-Control-Request-Method", http.MethodPost)
+	expectStatus(t, rec, http.StatusNoContent)
+	h := rec.Header()
+	if h.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		h.Get("Access-Control-Allow-Credentials") != "true" ||
+		h.Get("Access-Control-Max-Age") != "900" {
+		t.Errorf("preflight headers %v", h)
+	}
+
+	rec = tc.Do(http.MethodGet, "/users", "", "Origin", "https://evil.example.com")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("foreign origin allowed: %q", got)
+	}
+}
+
+func TestCORSWildcardWithCredentialsRejected(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("CORS_ALLOW_ORIGINS", "*")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	if _, err := loadConfig(); err == nil {
+		t.Error("wildcard origin with credentials accepted")
+	}
+}
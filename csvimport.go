@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// mimeCSV is the media type of user imports.
+const mimeCSV = "text/csv"
+
+// importColumns are the CSV columns an import row has, in any order.
+var importColumns = []string{"name", "age"}
+
+// ImportReport is the answer of POST /users/import/validate.
+type ImportReport struct {
+	Valid       bool              `json:"valid" example:"false"`
+	Rows        int               `json:"rows" example:"3"`
+	ValidRows   int               `json:"valid_rows" example:"2"`
+	InvalidRows int               `json:"invalid_rows" example:"1"`
+	Results     []ImportRowResult `json:"results"`
+}
+
+// ImportRowResult is the verdict on one CSV row. Line is the line the row
+// starts on, counting the header as line 1.
+type ImportRowResult struct {
+	Line     int          `json:"line" example:"2"`
+	Valid    bool         `json:"valid" example:"true"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	Warnings []FieldError `json:"warnings,omitempty"`
+}
+
+// ValidateImport godoc
+// @Summary      Validate a CSV import
+// @Description  Parses a CSV of users, with a header row naming the name and age columns, and validates every row as CreateUser would, without storing anything.
+// @Description  Names are checked against existing users and against earlier rows of the file. The report gives each row's line number and errors, with the codes of POST /users/validate plus INTEGER for an age that is not a number and COLUMNS for a row with the wrong number of fields.
+// @Tags         users
+// @Accept       text/csv
+// @Produce      json
+// @Param        file  body      string  true  "CSV with a name,age header row"
+// @Success      200   {object}  ImportReport
+// @Failure      400   {object}  map[string]string
+// @Failure      415   {object}  map[string]string
+// @Failure      422   {object}  ImportReport
+// @Router       /users/import/validate [post]
+func ValidateImport(c echo.Context) error {
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err != nil || mediaType != mimeCSV {
+		return c.JSON(http.StatusUnsupportedMediaType, echo.Map{"error": "Content-Type must be " + mimeCSV})
+	}
+
+	r := csv.NewReader(c.Request().Body)
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "CSV has no header row"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid CSV: " + err.Error()})
+	}
+	columns, err := importHeader(header)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	report := ImportReport{Results: []ImportRowResult{}}
+	// first line of every name seen so far, by NameKey
+	seen := map[string]int{}
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := r.FieldPos(0)
+		var row ImportRowResult
+		switch {
+		case errors.Is(err, csv.ErrFieldCount):
+			row = ImportRowResult{Line: line, Errors: []FieldError{{
+				Rule:    "columns",
+				Code:    ruleCode("columns"),
+				Message: fmt.Sprintf("row has %d fields, the header has %d", len(record), len(header)),
+			}}}
+		case err != nil:
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid CSV: " + err.Error()})
+		default:
+			row = validateImportRow(c, line, record, columns, seen)
+		}
+
+		row.Valid = len(row.Errors) == 0
+		if row.Valid {
+			report.ValidRows++
+		} else {
+			report.InvalidRows++
+		}
+		report.Results = append(report.Results, row)
+	}
+	report.Rows = len(report.Results)
+	report.Valid = report.InvalidRows == 0
+
+	if !report.Valid {
+		return c.JSON(http.StatusUnprocessableEntity, report)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// importHeader maps each of importColumns to its index in header. Column
+// names are matched case-insensitively; unknown, repeated and missing
+// columns are errors.
+func importHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// spreadsheets like to start the file with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(importColumns, name) {
+			return nil, fmt.Errorf("unknown column %q, expected %s", name, strings.Join(importColumns, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("column %q appears twice", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+	return columns, nil
+}
+
+// validateImportRow checks one record the way CreateUser checks a body,
+// plus the name against the earlier rows in seen, which it extends.
+func validateImportRow(c echo.Context, line int, record []string, columns map[string]int, seen map[string]int) ImportRowResult {
+	row := ImportRowResult{Line: line}
+	u := store.User{Name: record[columns["name"]]}
+
+	ageCell := strings.TrimSpace(record[columns["age"]])
+	if ageCell == "" {
+		row.Errors = append(row.Errors, FieldError{Field: "age", Rule: "present", Code: ruleCode("present"), Message: "age is required"})
+	} else if age, err := store.ParseAge(json.RawMessage(strconv.Quote(ageCell))); err != nil {
+		row.Errors = append(row.Errors, FieldError{Field: "age", Rule: "integer", Code: ruleCode("integer"), Message: err.Error()})
+	} else {
+		u.Age = age
+	}
+
+	if err := validateRequest(c, &u); err != nil {
+		row.Errors = append(row.Errors, fieldErrors(err)...)
+	}
+
+	if u.Name != "" {
+		key := store.NameKey(u.Name)
+		if first, ok := seen[key]; ok {
+			row.Errors = append(row.Errors, FieldError{
+				Field:   "name",
+				Rule:    "unique_name",
+				Code:    ruleCode("unique_name"),
+				Message: fmt.Sprintf("name duplicates line %d", first),
+			})
+		} else {
+			seen[key] = line
+		}
+	}
+
+	if len(row.Errors) == 0 {
+		row.Warnings = userWarnings(u)
+	}
+	return row
+}
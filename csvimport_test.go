@@ -0,0 +1,96 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// validateCSV posts body to the import validation and returns the report,
+// failing unless the status is status.
+func validateCSV(tc *testClient, body string, status int) ImportReport {
+	tc.t.Helper()
+	rec := tc.Do(http.MethodPost, "/users/import/validate", body, echo.HeaderContentType, "text/csv; charset=utf-8")
+	expectStatus(tc.t, rec, status)
+	return decode[ImportReport](tc.t, rec)
+}
+
+func TestValidateImportClean(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	report := validateCSV(tc, "\ufeffAge,Name\n31,Dewi\n40,\"Prasetyo, Eko\"\n0,Fajar\n", http.StatusOK)
+	if !report.Valid || report.Rows != 3 || report.ValidRows != 3 || report.InvalidRows != 0 {
+		t.Errorf("report %+v", report)
+	}
+	// the BOM, the column order and the quoted comma are all fine
+	for i, line := range []int{2, 3, 4} {
+		if report.Results[i].Line != line || !report.Results[i].Valid {
+			t.Errorf("row %d: %+v, want valid on line %d", i, report.Results[i], line)
+		}
+	}
+
+	// nothing was imported
+	rec := tc.Do(http.MethodGet, "/users", "")
+	if page := decode[Page[store.User]](t, rec); page.Total != 3 {
+		t.Errorf("%d users after validating, want 3", page.Total)
+	}
+}
+
+func TestValidateImportDuplicates(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	report := validateCSV(tc, "name,age\nDewi,31\nBAGUS,20\ndewi,32\nEko,40\n", http.StatusUnprocessableEntity)
+	if report.Valid || report.Rows != 4 || report.ValidRows != 2 || report.InvalidRows != 2 {
+		t.Errorf("report %+v", report)
+	}
+	for _, i := range []int{1, 2} {
+		row := report.Results[i]
+		if row.Valid || len(row.Errors) != 1 || row.Errors[0].Field != "name" || row.Errors[0].Code != "UNIQUE_NAME" {
+			t.Errorf("line %d: %+v, want one UNIQUE_NAME", row.Line, row)
+		}
+	}
+	if msg := report.Results[2].Errors[0].Message; msg != "name duplicates line 2" {
+		t.Errorf("within-file duplicate message %q", msg)
+	}
+}
+
+func TestValidateImportInvalidRows(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	report := validateCSV(tc, "name,age\n,31\nDewi,abc\nEko,-1\nFajar\nGita,\n\"Hana\nH\",20\nIka,x\n", http.StatusUnprocessableEntity)
+	want := []struct {
+		line        int
+		field, code string
+	}{
+		{2, "name", "REQUIRED"},
+		{3, "age", "INTEGER"},
+		{4, "age", "MIN"},
+		{5, "", "COLUMNS"},
+		{6, "age", "REQUIRED"},
+		// the quoted name spans lines 7 and 8
+		{7, "name", "NO_CONTROL"},
+		{9, "age", "INTEGER"},
+	}
+	if report.Rows != len(want) || report.InvalidRows != len(want) {
+		t.Fatalf("report %+v", report)
+	}
+	for i, w := range want {
+		row := report.Results[i]
+		if row.Line != w.line || row.Valid || len(row.Errors) != 1 || row.Errors[0].Field != w.field || row.Errors[0].Code != w.code {
+			t.Errorf("row %d: %+v, want %s on %q at line %d", i, row, w.code, w.field, w.line)
+		}
+	}
+}
+
+func TestValidateImportRejectsBadInput(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	expectStatus(t, tc.Do(http.MethodPost, "/users/import/validate", `[{"name":"Dewi","age":31}]`), http.StatusUnsupportedMediaType)
+	for _, body := range []string{"", "name,age,email\nDewi,31,d@example.com\n", "name,name\n", "age\n31\n"} {
+		rec := tc.Do(http.MethodPost, "/users/import/validate", body, echo.HeaderContentType, "text/csv")
+		expectStatus(t, rec, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultNameParticles are the words displayName keeps lower-case inside a
+// name when DISPLAY_NAME_PARTICLES is not set.
+var defaultNameParticles = []string{"bin", "binti", "da", "de", "del", "della", "der", "di", "du", "la", "le", "van", "von"}
+
+// displayName returns a formatter that title-cases names for display. Each
+// word, and each part of a hyphenated word, gets an upper-case first letter.
+// The rest is lower-cased only when the word was written all in capitals, so
+// "AGUS" becomes "Agus" and "McDonald" stays as it is. Particles are kept
+// lower-case except as the first word: "ludwig VAN beethoven" becomes
+// "Ludwig van Beethoven".
+func displayName(particles []string) func(string) string {
+	isParticle := make(map[string]bool, len(particles))
+	for _, p := range particles {
+		isParticle[strings.ToLower(p)] = true
+	}
+
+	return func(name string) string {
+		words := strings.Fields(name)
+		for i, word := range words {
+			if i > 0 && isParticle[strings.ToLower(word)] {
+				words[i] = strings.ToLower(word)
+				continue
+			}
+			parts := strings.Split(word, "-")
+			for j, part := range parts {
+				parts[j] = capitalize(part)
+			}
+			words[i] = strings.Join(parts, "-")
+		}
+		return strings.Join(words, " ")
+	}
+}
+
+// capitalize upper-cases the first letter of word, lower-casing the rest if
+// word is all capitals.
+func capitalize(word string) string {
+	first, size := utf8.DecodeRuneInString(word)
+	if first == utf8.RuneError {
+		return word
+	}
+	rest := word[size:]
+	if rest == strings.ToUpper(rest) {
+		rest = strings.ToLower(rest)
+	}
+	return string(unicode.ToTitle(first)) + rest
+}
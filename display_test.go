@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"go-echo/store"
+)
+
+func TestDisplayName(t *testing.T) {
+	format := displayName(defaultNameParticles)
+	for name, want := range map[string]string{
+		"ludwig VAN beethoven": "Ludwig van Beethoven",
+		"AGUS SANTOSO":         "Agus Santoso",
+		"siti binti hasan":     "Siti binti Hasan",
+		"van halen":            "Van Halen",
+		"jean-luc picard":      "Jean-Luc Picard",
+		"McDonald":             "McDonald",
+		"Agus Santoso":         "Agus Santoso",
+		"  agus   santoso ":    "Agus Santoso",
+	} {
+		if got := format(name); got != want {
+			t.Errorf("displayName(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if got := displayName([]string{"al"})("omar AL farouq de silva"); got != "Omar al Farouq De Silva" {
+		t.Errorf("custom particles: %q", got)
+	}
+}
+
+func TestDisplayNamesKeepTheOriginal(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.DisplayNames = true
+	})
+
+	created := tc.CreateUser(store.User{Name: "ludwig VAN beethoven", Age: 56})
+	if created.Name != "ludwig VAN beethoven" || created.DisplayName != "Ludwig van Beethoven" {
+		t.Errorf("created %q shown as %q", created.Name, created.DisplayName)
+	}
+	// a client cannot set it
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `{"name":"agus santoso","display_name":"X"}`), http.StatusOK)
+	if u := tc.GetUser(1); u.DisplayName != "Agus Santoso" {
+		t.Errorf("after rename shown as %q", u.DisplayName)
+	}
+
+	off := newTestClient(t, nil)
+	if u := off.GetUser(1); u.DisplayName != "" {
+		t.Errorf("display_name %q while off", u.DisplayName)
+	}
+}
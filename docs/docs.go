@@ -15,56 +15,111 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/users": {
+        "/admin/config": {
             "get": {
-                "description": "Retrieves a list of all users",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the configuration in effect, including reloaded values. Secrets are shown as \"[REDACTED]\" when set and empty otherwise.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Get all users",
+                "summary": "Show effective configuration",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/main.User"
+                            "$ref": "#/definitions/main.ConfigView"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/reload": {
             "post": {
-                "description": "Creates a new user with the provided details",
-                "consumes": [
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Re-reads the hot-reloadable settings (LOG_LEVEL, DEFAULT_PAGE_LIMIT, MAX_PAGE_LIMIT) from the environment and applies them",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reload configuration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Config"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/repair": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Scans the tenant's users for duplicate or non-positive IDs, negative ages, blank names and names that collide case-insensitively. Bad IDs are moved to fresh ones; the other anomalies are only reported.\nOnly registered when APP_ENV is development. With dry_run=true the report is produced but nothing changes.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Create a new user",
+                "summary": "Check and repair the dataset",
                 "parameters": [
                     {
-                        "description": "User to create",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.User"
-                        }
+                        "type": "boolean",
+                        "description": "Report without fixing",
+                        "name": "dry_run",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.User"
+                            "$ref": "#/definitions/store.RepairReport"
                         }
                     },
                     "400": {
@@ -75,34 +130,91 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/users/{id}": {
+        "/capabilities": {
             "get": {
-                "description": "Retrieves a user by ID",
+                "description": "Lists the optional features, formats, auth schemes and limits in effect, derived from the configuration",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "meta"
                 ],
-                "summary": "Get user by ID",
+                "summary": "API capabilities",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Capabilities"
+                        }
+                    }
+                }
+            }
+        },
+        "/changelog": {
+            "get": {
+                "description": "Lists the committed creates, updates and deletes of the tenant's users, oldest first, optionally only one action or a time range. Merges appear as an update of the target and a delete of the source.\nOnly the most recent 10000 changes are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "changelog"
+                ],
+                "summary": "Changelog of all users",
                 "parameters": [
+                    {
+                        "enum": [
+                            "create",
+                            "update",
+                            "delete"
+                        ],
+                        "type": "string",
+                        "description": "Only this action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes at or after this RFC3339 time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes before this RFC3339 time",
+                        "name": "until",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default DEFAULT_PAGE_LIMIT, max MAX_PAGE_LIMIT)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.User"
+                            "$ref": "#/definitions/main.Page-store_Change"
                         }
                     },
                     "400": {
@@ -113,9 +225,37 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    }
+                }
+            }
+        },
+        "/changelog/tail": {
+            "get": {
+                "description": "Streams the tenant's changes as server-sent events as they are committed: one \"change\" event per Change, with its seq as the event ID.\nA client reconnecting with Last-Event-ID first gets the changes after that ID that are still held in the changelog (the most recent 10000), then live ones; older ones are gone. Without the header the stream starts with the next change.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "changelog"
+                ],
+                "summary": "Live changelog",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Seq of the last change received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event stream",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -125,42 +265,118 @@ const docTemplate = `{
                     }
                 }
             }
-            ,
-            "put": {
-                "description": "Updates user data for the given ID",
-                "consumes": [
+        },
+        "/healthz": {
+            "get": {
+                "description": "Reports that the process is up",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meta"
+                ],
+                "summary": "Liveness",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz/detailed": {
+            "get": {
+                "description": "Times a full listing of the default tenant's store. A listing that fails reports the store as failed, and one slower than HEALTH_STORE_THRESHOLD, or still running at it, as degraded; either way with 503.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "meta"
+                ],
+                "summary": "Health with store latency",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.HealthReport"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/main.HealthReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Retrieves a page of users, optionally only those changed since a point in time.\nA page past the last one returns 200 with empty data and out_of_range set.\nInstead of page/limit a \"Range: items=0-49\" header may be sent; the window, of at most MAX_PAGE_LIMIT items, is then returned as a bare array with 206 and a Content-Range header. A Range in another unit is ignored.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "users"
                 ],
-                "summary": "Update existing user",
+                "summary": "Get all users",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item window, e.g. items=0-49",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Strong ETag of a cached window, as sent with the 206; the Range is only honored if it still matches",
+                        "name": "If-Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only users created or updated at or after this RFC3339 time",
+                        "name": "modified_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort keys (id, name, age), prefix with - for descending, e.g. age,-name (default DEFAULT_SORT)",
+                        "name": "sort",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
-                        "description": "Updated user data",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.User"
-                        }
+                        "type": "integer",
+                        "description": "Page size (default DEFAULT_PAGE_LIMIT, max MAX_PAGE_LIMIT)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.User"
+                            "$ref": "#/definitions/main.Page-store_User"
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.User"
+                            }
                         }
                     },
                     "400": {
@@ -172,8 +388,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "416": {
+                        "description": "Requested Range Not Satisfiable",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -183,27 +399,62 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "Deletes a user by ID",
+            "post": {
+                "description": "Creates a new user with the provided details. IDs are server-assigned; a body with a non-zero id is rejected with 400.\nWith dry_run=true the user is validated and checked for conflicts and the would-be result is returned with 200, but nothing is stored.\nValues that are allowed but unusual, such as an age above AGE_WARN_ABOVE, are reported in warnings without failing the request.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "users"
                 ],
-                "summary": "Delete user by ID",
+                "summary": "Create a new user",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "User to create",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Preview without committing",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "return=minimal for 204 without a body (ignored with dry_run), or return=representation (default)",
+                        "name": "Prefer",
+                        "in": "header"
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "Dry run preview",
+                        "schema": {
+                            "$ref": "#/definitions/main.UserResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.UserResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created user"
+                            }
+                        }
+                    },
                     "204": {
-                        "description": "No Content"
+                        "description": "Prefer: return=minimal"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -214,8 +465,26 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "507": {
+                        "description": "MAX_USERS reached, or MAX_TENANTS by a new tenant's first user",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -225,27 +494,2481 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "main.User": {
-            "type": "object",
-            "required": [
-                "age",
-                "name"
-            ],
-            "properties": {
-                "age": {
-                    "type": "integer",
-                    "minimum": 0
-                },
-                "id": {
-                    "type": "integer"
+        },
+        "/users.html": {
+            "get": {
+                "description": "Renders a page of users as an HTML table with previous/next links. Takes the same modified_since, sort, page and limit parameters as GET /users.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Browse users as HTML",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only users created or updated at or after this RFC3339 time",
+                        "name": "modified_since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort keys (id, name, age), prefix with - for descending (default DEFAULT_SORT)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default DEFAULT_PAGE_LIMIT, max MAX_PAGE_LIMIT)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/autocomplete": {
+            "get": {
+                "description": "Lists users whose name starts with q (case-insensitive), sorted alphabetically",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Autocomplete user names",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name prefix",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum suggestions (default 10, max MAX_PAGE_LIMIT)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.NameSuggestion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/bulk-set": {
+            "post": {
+                "description": "Sets field to value on every user in ids in one step: either all of them are updated or, on a conflict, none are. IDs that do not exist are reported in not_found and skipped.\nThe value is checked against the same rules as PATCH /users/{id}; read-only fields such as id are rejected. With dry_run=true nothing is stored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set one field on listed users",
+                "parameters": [
+                    {
+                        "description": "IDs, field and value",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.BulkSetRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Preview without committing",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.BulkSetResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/bulk-update": {
+            "post": {
+                "description": "Applies the changes to every user matching the filter in one step: either all matches are updated or, on a conflict, none are.\nRenaming more than one user to the same name is a conflict. With dry_run=true the matches are reported but nothing is stored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update all users matching a filter",
+                "parameters": [
+                    {
+                        "description": "Filter and changes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.BulkUpdateRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Preview without committing",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.BulkUpdateResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/by-name/{name}": {
+            "get": {
+                "description": "Returns the user with the given name, compared case-insensitively and Unicode-normalized like the uniqueness check. Names are unique in that form, so at most one user matches.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user by name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/compare": {
+            "get": {
+                "description": "Returns users a and b side by side with a per-field diff of name and age, e.g. to review a merge",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Compare two users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "First user ID",
+                        "name": "a",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Second user ID",
+                        "name": "b",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UserComparison"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/exists": {
+            "get": {
+                "description": "Reports whether a user with the given name (case-insensitive) exists",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Check whether a name is taken",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/exists-batch": {
+            "post": {
+                "description": "Reports for each given name whether a user with that name (case-insensitive) exists. At most 100 names per request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Check which names are taken",
+                "parameters": [
+                    {
+                        "description": "Names to check",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.ExistsBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/extremes": {
+            "get": {
+                "description": "Returns the youngest and the oldest user, ties broken by lowest ID. With no users both fields are null.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Youngest and oldest users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Extremes"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/get-batch": {
+            "post": {
+                "description": "Returns an object keyed by each requested ID, mapping to the user or to null when there is no user with that ID. All users are read from the same snapshot. At most 100 IDs per request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Fetch users by ID",
+                "parameters": [
+                    {
+                        "description": "IDs to fetch",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.GetBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/store.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/import/validate": {
+            "post": {
+                "description": "Parses a CSV of users, with a header row naming the name and age columns, and validates every row as CreateUser would, without storing anything.\nNames are checked against existing users and against earlier rows of the file. The report gives each row's line number and errors, with the codes of POST /users/validate plus INTEGER for an age that is not a number and COLUMNS for a row with the wrong number of fields.",
+                "consumes": [
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Validate a CSV import",
+                "parameters": [
+                    {
+                        "description": "CSV with a name,age header row",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ImportReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/name-counts": {
+            "get": {
+                "description": "Lists each distinct name (case-insensitive) with the number of users sharing it, most common first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Count users per name",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default DEFAULT_PAGE_LIMIT, max MAX_PAGE_LIMIT)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Page-main_NameCount"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/next-id": {
+            "get": {
+                "description": "Returns the ID the next POST /users would assign. This is advisory: nothing is reserved, so a concurrent create can take it first. IDs are never reused, deleted ones included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Next user ID",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/recent": {
+            "get": {
+                "description": "Lists the most recently created users, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Recently created users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum users (default 10, max MAX_PAGE_LIMIT)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/search": {
+            "post": {
+                "description": "Filters users by name substring and age range, with sorting and pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Search users",
+                "parameters": [
+                    {
+                        "description": "Search query",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SearchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Page-store_User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/validate": {
+            "post": {
+                "description": "Runs the same binding and validation as CreateUser, including the name uniqueness check, without storing anything\nEach error and warning carries a stable code to match on instead of the message: REQUIRED, MIN, MAX, ONE_OF, NO_CONTROL, UNIQUE_NAME, or AGE_WARN_ABOVE for the age warning.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Validate a user payload",
+                "parameters": [
+                    {
+                        "description": "User to validate",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ValidationResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ValidationResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Retrieves a user by ID.\nWith \"Accept: text/vcard\" the user is returned as a vCard 3.0 download instead.",
+                "produces": [
+                    "application/json",
+                    "text/vcard"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.NotFoundResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Updates user data for the given ID.\nWith dry_run=true the update is validated and previewed but not stored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update existing user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated user data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Preview without committing",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "return=minimal for 204 without a body (ignored with dry_run), or return=representation (default)",
+                        "name": "Prefer",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UserResponse"
+                        }
+                    },
+                    "204": {
+                        "description": "Prefer: return=minimal"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.NotFoundResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a user by the given ID and returns the deleted user.\nDeletion is permanent, so repeating it for the same ID returns 404.\nWith dry_run=true the user that would be deleted is returned but kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Preview without committing",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.NotFoundResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Updates only the fields present in the body; omitted fields keep their current value.\nAn RFC 6902 document (array of {op, path, value}) is accepted instead with Content-Type application/json-patch+json; add, replace and remove are supported on /name and /age.\nWith dry_run=true the update is validated and previewed but not stored.",
+                "consumes": [
+                    "application/json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Partially update user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/store.UserPatch"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Preview without committing",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "return=minimal for 204 without a body (ignored with dry_run), or return=representation (default)",
+                        "name": "Prefer",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UserResponse"
+                        }
+                    },
+                    "204": {
+                        "description": "Prefer: return=minimal"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.NotFoundResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/clone": {
+            "post": {
+                "description": "Creates a new user with the source's name suffixed with \" (copy)\" and its age. If that name is taken, \" (copy 2)\", \" (copy 3)\" and so on are tried.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Duplicate a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Source user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created user"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.NotFoundResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "507": {
+                        "description": "MAX_USERS reached",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/json-schema": {
+            "get": {
+                "description": "Describes the fields of the user a client may change, with their constraints and the user's current values as defaults, for generic form builders",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "JSON Schema of a user's editable fields",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.JSONSchema"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/merge": {
+            "post": {
+                "description": "Merges the source user into the target: the target keeps its values except for fields listed in take_from_source, and the source is deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Merge two users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Source user and field overrides",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.MergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.NotFoundResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/similar": {
+            "get": {
+                "description": "Lists up to count other users ordered by absolute age difference to the given user, then by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Users closest in age",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum users (default 5, max MAX_PAGE_LIMIT)",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Returns the version, git commit and build date injected via ldflags, and the Go runtime version",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meta"
+                ],
+                "summary": "Build information",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.VersionInfo"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "main.BulkSetRequest": {
+            "type": "object",
+            "required": [
+                "field",
+                "ids"
+            ],
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "age"
+                },
+                "ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        3
+                    ]
+                },
+                "value": {
+                    "type": "object"
+                }
+            }
+        },
+        "main.BulkSetResult": {
+            "type": "object",
+            "properties": {
+                "not_found": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        7
+                    ]
+                },
+                "updated": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        3
+                    ]
+                }
+            }
+        },
+        "main.BulkUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "$ref": "#/definitions/store.UserPatch"
+                },
+                "filter": {
+                    "$ref": "#/definitions/main.UserFilter"
+                }
+            }
+        },
+        "main.BulkUpdateResult": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        3
+                    ]
+                },
+                "updated": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "main.Capabilities": {
+            "type": "object",
+            "properties": {
+                "auth": {
+                    "description": "Auth lists the authentication schemes in use: \"bearer\" for /admin\nwhen an admin token is set.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "bearer"
+                    ]
+                },
+                "features": {
+                    "description": "Features reports each DISABLED_FEATURES flag as enabled or not.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "formats": {
+                    "description": "Formats are the media types the API can produce or accept.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "application/json",
+                        "text/vcard"
+                    ]
+                },
+                "gzip": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "h2c": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "idempotency": {
+                    "description": "Idempotency is set when writes accept an Idempotency-Key.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "max_url_length": {
+                    "description": "MaxURLLength is the longest accepted URL in bytes; 0 means no limit.",
+                    "type": "integer",
+                    "example": 2048
+                },
+                "pagination": {
+                    "$ref": "#/definitions/main.PaginationCapabilities"
+                },
+                "swagger": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "tenant_header": {
+                    "description": "TenantHeader names the header selecting the tenant; Required is set\nin strict mode.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.TenantHeaderCapability"
+                        }
+                    ]
+                }
+            }
+        },
+        "main.Config": {
+            "type": "object",
+            "properties": {
+                "age_warn_above": {
+                    "description": "AgeWarnAbove is the age above which a write succeeds with a warning\nin the response (AGE_WARN_ABOVE). Zero turns the warning off.",
+                    "type": "integer"
+                },
+                "cors_allow_credentials": {
+                    "description": "CORSAllowCredentials sends Access-Control-Allow-Credentials\n(CORS_ALLOW_CREDENTIALS). Only allowed with explicit origins.",
+                    "type": "boolean"
+                },
+                "cors_allow_origins": {
+                    "description": "CORSAllowOrigins lists the origins allowed to call the API\n(CORS_ALLOW_ORIGINS, comma-separated). CORS is off when empty.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "cors_max_age": {
+                    "description": "CORSMaxAge is how long, in seconds, browsers may cache a preflight\n(CORS_MAX_AGE).",
+                    "type": "integer"
+                },
+                "default_page_limit": {
+                    "description": "DefaultPageLimit is the page size used when limit is not given\n(DEFAULT_PAGE_LIMIT).",
+                    "type": "integer"
+                },
+                "default_sort": {
+                    "description": "DefaultSort is the order GET /users uses when no sort is given\n(DEFAULT_SORT), in the same syntax as the sort parameter, e.g. \"-id\"\nfor newest first.",
+                    "type": "string"
+                },
+                "disabled_features": {
+                    "description": "DisabledFeatures switches optional endpoints off\n(DISABLED_FEATURES, comma-separated); their routes answer 404. The\nflag names are html, bulk, merge, clone, similar, schema, next_id and\nreports, see features for the routes each covers.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "display_name_particles": {
+                    "description": "DisplayNameParticles are the words kept lower-case inside a display\nname (DISPLAY_NAME_PARTICLES, comma-separated), e.g. de, van.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "display_names": {
+                    "description": "DisplayNames adds a title-cased display_name to every user\n(DISPLAY_NAMES). Off by default; names are stored as entered either\nway.",
+                    "type": "boolean"
+                },
+                "env": {
+                    "description": "Env is the deployment environment, \"development\" or \"production\"\n(APP_ENV). It selects defaults for other settings.",
+                    "type": "string"
+                },
+                "force_https": {
+                    "description": "ForceHTTPS redirects or rejects plaintext requests and sends HSTS\n(FORCE_HTTPS). Off by default; only enable it behind a proxy that sets\nX-Forwarded-Proto.",
+                    "type": "boolean"
+                },
+                "gzip": {
+                    "description": "Gzip compresses responses for clients that accept it (GZIP). Off by\ndefault.",
+                    "type": "boolean"
+                },
+                "h2c": {
+                    "description": "H2C also serves HTTP/2 over cleartext connections (H2C), for internal\ncallers that multiplex without TLS. Off by default.",
+                    "type": "boolean"
+                },
+                "health_store_threshold": {
+                    "description": "HealthStoreThreshold is the store latency above which\n/healthz/detailed reports degraded (HEALTH_STORE_THRESHOLD, a Go\nduration). JSON shows it in nanoseconds.",
+                    "type": "integer",
+                    "example": 100000000
+                },
+                "hsts_max_age": {
+                    "description": "HSTSMaxAge is the Strict-Transport-Security max-age in seconds\n(HSTS_MAX_AGE).",
+                    "type": "integer"
+                },
+                "idempotency_max_keys": {
+                    "description": "IdempotencyMaxKeys caps the stored responses (IDEMPOTENCY_MAX_KEYS);\npast it the ones closest to expiry are dropped early.",
+                    "type": "integer"
+                },
+                "idempotency_ttl": {
+                    "description": "IdempotencyTTL is how long a write response is kept for replay to\nretries with the same Idempotency-Key (IDEMPOTENCY_TTL, a Go\nduration). Zero turns the Idempotency-Key handling off. JSON shows it\nin nanoseconds.",
+                    "type": "integer",
+                    "example": 86400000000000
+                },
+                "json_escape_html": {
+                    "description": "JSONEscapeHTML escapes \u003c, \u003e and \u0026 in JSON responses\n(JSON_ESCAPE_HTML). Defaults to true; only turn it off when responses\nare never embedded in HTML unescaped.",
+                    "type": "boolean"
+                },
+                "log_bodies": {
+                    "description": "LogBodies turns on request/response body logging for the API routes\n(LOG_BODIES). Off by default.",
+                    "type": "boolean"
+                },
+                "log_body_max_bytes": {
+                    "description": "LogBodyMaxBytes caps how much of each logged body is kept\n(LOG_BODY_MAX_BYTES).",
+                    "type": "integer"
+                },
+                "log_level": {
+                    "description": "LogLevel is one of debug, info, warn, error or off (LOG_LEVEL). The\ndefault, warn, shows the slow request and retry storm warnings.",
+                    "type": "string"
+                },
+                "max_page_limit": {
+                    "description": "MaxPageLimit is the largest accepted limit (MAX_PAGE_LIMIT).",
+                    "type": "integer"
+                },
+                "max_tenants": {
+                    "description": "MaxTenants caps the tenants created from the tenant header, besides\nthe default one (MAX_TENANTS). A tenant is created by its first user;\npast the cap that create gets 507, while reads of an unknown tenant\nsee no users. Zero means unlimited.",
+                    "type": "integer"
+                },
+                "max_url_length": {
+                    "description": "MaxURLLength is the longest request URL, path plus query, accepted\nbefore answering 414 (MAX_URL_LENGTH). Zero turns the check off.",
+                    "type": "integer"
+                },
+                "max_users": {
+                    "description": "MaxUsers caps the number of stored users, across all tenants\n(MAX_USERS); creating past it returns 507. Zero means unlimited.",
+                    "type": "integer"
+                },
+                "problem_json": {
+                    "description": "ProblemJSON renders every JSON error as application/problem+json\n(RFC 7807) rather than only for clients asking for it in Accept\n(PROBLEM_JSON). Off by default.",
+                    "type": "boolean"
+                },
+                "request_id_header": {
+                    "description": "RequestIDHeader is the header RequestID reads and echoes\n(REQUEST_ID_HEADER), e.g. X-Correlation-ID.",
+                    "type": "string"
+                },
+                "required_header": {
+                    "description": "RequiredHeader is the header strict mode insists on\n(REQUIRED_HEADER), injected by the gateway. Its value names the tenant\nwhose users a request sees.",
+                    "type": "string"
+                },
+                "retry_storm_threshold": {
+                    "description": "RetryStormThreshold is how many identical requests (method, URL and\nbody) from one client within RetryStormWindow get logged at WARN as a\nlikely retry loop (RETRY_STORM_THRESHOLD). Zero, the default, turns\nthe log off. The warnings need LOG_LEVEL warn or lower, the default.",
+                    "type": "integer"
+                },
+                "retry_storm_window": {
+                    "description": "RetryStormWindow is the window RetryStormThreshold counts in\n(RETRY_STORM_WINDOW, a Go duration). JSON shows it in nanoseconds.",
+                    "type": "integer",
+                    "example": 10000000000
+                },
+                "server_timing": {
+                    "description": "ServerTiming adds a Server-Timing header with handler and store\ndurations (SERVER_TIMING). Off by default.",
+                    "type": "boolean"
+                },
+                "shutdown_timeout": {
+                    "description": "ShutdownTimeout is how long in-flight requests get to finish once\nSIGINT or SIGTERM arrives (SHUTDOWN_TIMEOUT, a Go duration); the\nconnections of those still running then are closed. Keep it below\nthe deployment's grace period. JSON shows it in nanoseconds.",
+                    "type": "integer",
+                    "example": 10000000000
+                },
+                "slow_request_threshold": {
+                    "description": "SlowRequestThreshold is the latency above which a request is logged\nat WARN (SLOW_REQUEST_THRESHOLD, a Go duration such as 500ms), which\nthe default LOG_LEVEL shows. Zero turns the log off. JSON shows it in\nnanoseconds.",
+                    "type": "integer",
+                    "example": 500000000
+                },
+                "strict_mode": {
+                    "description": "StrictMode rejects API requests without RequiredHeader with 400\n(STRICT_MODE). Off by default.",
+                    "type": "boolean"
+                },
+                "swagger_enabled": {
+                    "description": "SwaggerEnabled registers the /swagger UI and the raw spec at\n/openapi.json and /openapi.yaml (SWAGGER_ENABLED). Defaults to true, or\nfalse when Env is production.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.ConfigView": {
+            "type": "object",
+            "properties": {
+                "admin_token": {
+                    "type": "string",
+                    "example": "[REDACTED]"
+                },
+                "age_warn_above": {
+                    "description": "AgeWarnAbove is the age above which a write succeeds with a warning\nin the response (AGE_WARN_ABOVE). Zero turns the warning off.",
+                    "type": "integer"
+                },
+                "cors_allow_credentials": {
+                    "description": "CORSAllowCredentials sends Access-Control-Allow-Credentials\n(CORS_ALLOW_CREDENTIALS). Only allowed with explicit origins.",
+                    "type": "boolean"
+                },
+                "cors_allow_origins": {
+                    "description": "CORSAllowOrigins lists the origins allowed to call the API\n(CORS_ALLOW_ORIGINS, comma-separated). CORS is off when empty.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "cors_max_age": {
+                    "description": "CORSMaxAge is how long, in seconds, browsers may cache a preflight\n(CORS_MAX_AGE).",
+                    "type": "integer"
+                },
+                "default_page_limit": {
+                    "description": "DefaultPageLimit is the page size used when limit is not given\n(DEFAULT_PAGE_LIMIT).",
+                    "type": "integer"
+                },
+                "default_sort": {
+                    "description": "DefaultSort is the order GET /users uses when no sort is given\n(DEFAULT_SORT), in the same syntax as the sort parameter, e.g. \"-id\"\nfor newest first.",
+                    "type": "string"
+                },
+                "disabled_features": {
+                    "description": "DisabledFeatures switches optional endpoints off\n(DISABLED_FEATURES, comma-separated); their routes answer 404. The\nflag names are html, bulk, merge, clone, similar, schema, next_id and\nreports, see features for the routes each covers.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "display_name_particles": {
+                    "description": "DisplayNameParticles are the words kept lower-case inside a display\nname (DISPLAY_NAME_PARTICLES, comma-separated), e.g. de, van.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "display_names": {
+                    "description": "DisplayNames adds a title-cased display_name to every user\n(DISPLAY_NAMES). Off by default; names are stored as entered either\nway.",
+                    "type": "boolean"
+                },
+                "env": {
+                    "description": "Env is the deployment environment, \"development\" or \"production\"\n(APP_ENV). It selects defaults for other settings.",
+                    "type": "string"
+                },
+                "force_https": {
+                    "description": "ForceHTTPS redirects or rejects plaintext requests and sends HSTS\n(FORCE_HTTPS). Off by default; only enable it behind a proxy that sets\nX-Forwarded-Proto.",
+                    "type": "boolean"
+                },
+                "gzip": {
+                    "description": "Gzip compresses responses for clients that accept it (GZIP). Off by\ndefault.",
+                    "type": "boolean"
+                },
+                "h2c": {
+                    "description": "H2C also serves HTTP/2 over cleartext connections (H2C), for internal\ncallers that multiplex without TLS. Off by default.",
+                    "type": "boolean"
+                },
+                "health_store_threshold": {
+                    "description": "HealthStoreThreshold is the store latency above which\n/healthz/detailed reports degraded (HEALTH_STORE_THRESHOLD, a Go\nduration). JSON shows it in nanoseconds.",
+                    "type": "integer",
+                    "example": 100000000
+                },
+                "hsts_max_age": {
+                    "description": "HSTSMaxAge is the Strict-Transport-Security max-age in seconds\n(HSTS_MAX_AGE).",
+                    "type": "integer"
+                },
+                "idempotency_max_keys": {
+                    "description": "IdempotencyMaxKeys caps the stored responses (IDEMPOTENCY_MAX_KEYS);\npast it the ones closest to expiry are dropped early.",
+                    "type": "integer"
+                },
+                "idempotency_ttl": {
+                    "description": "IdempotencyTTL is how long a write response is kept for replay to\nretries with the same Idempotency-Key (IDEMPOTENCY_TTL, a Go\nduration). Zero turns the Idempotency-Key handling off. JSON shows it\nin nanoseconds.",
+                    "type": "integer",
+                    "example": 86400000000000
+                },
+                "json_escape_html": {
+                    "description": "JSONEscapeHTML escapes \u003c, \u003e and \u0026 in JSON responses\n(JSON_ESCAPE_HTML). Defaults to true; only turn it off when responses\nare never embedded in HTML unescaped.",
+                    "type": "boolean"
+                },
+                "log_bodies": {
+                    "description": "LogBodies turns on request/response body logging for the API routes\n(LOG_BODIES). Off by default.",
+                    "type": "boolean"
+                },
+                "log_body_max_bytes": {
+                    "description": "LogBodyMaxBytes caps how much of each logged body is kept\n(LOG_BODY_MAX_BYTES).",
+                    "type": "integer"
+                },
+                "log_level": {
+                    "description": "LogLevel is one of debug, info, warn, error or off (LOG_LEVEL). The\ndefault, warn, shows the slow request and retry storm warnings.",
+                    "type": "string"
+                },
+                "max_page_limit": {
+                    "description": "MaxPageLimit is the largest accepted limit (MAX_PAGE_LIMIT).",
+                    "type": "integer"
+                },
+                "max_tenants": {
+                    "description": "MaxTenants caps the tenants created from the tenant header, besides\nthe default one (MAX_TENANTS). A tenant is created by its first user;\npast the cap that create gets 507, while reads of an unknown tenant\nsee no users. Zero means unlimited.",
+                    "type": "integer"
+                },
+                "max_url_length": {
+                    "description": "MaxURLLength is the longest request URL, path plus query, accepted\nbefore answering 414 (MAX_URL_LENGTH). Zero turns the check off.",
+                    "type": "integer"
+                },
+                "max_users": {
+                    "description": "MaxUsers caps the number of stored users, across all tenants\n(MAX_USERS); creating past it returns 507. Zero means unlimited.",
+                    "type": "integer"
+                },
+                "problem_json": {
+                    "description": "ProblemJSON renders every JSON error as application/problem+json\n(RFC 7807) rather than only for clients asking for it in Accept\n(PROBLEM_JSON). Off by default.",
+                    "type": "boolean"
+                },
+                "request_id_header": {
+                    "description": "RequestIDHeader is the header RequestID reads and echoes\n(REQUEST_ID_HEADER), e.g. X-Correlation-ID.",
+                    "type": "string"
+                },
+                "required_header": {
+                    "description": "RequiredHeader is the header strict mode insists on\n(REQUIRED_HEADER), injected by the gateway. Its value names the tenant\nwhose users a request sees.",
+                    "type": "string"
+                },
+                "retry_storm_threshold": {
+                    "description": "RetryStormThreshold is how many identical requests (method, URL and\nbody) from one client within RetryStormWindow get logged at WARN as a\nlikely retry loop (RETRY_STORM_THRESHOLD). Zero, the default, turns\nthe log off. The warnings need LOG_LEVEL warn or lower, the default.",
+                    "type": "integer"
+                },
+                "retry_storm_window": {
+                    "description": "RetryStormWindow is the window RetryStormThreshold counts in\n(RETRY_STORM_WINDOW, a Go duration). JSON shows it in nanoseconds.",
+                    "type": "integer",
+                    "example": 10000000000
+                },
+                "server_timing": {
+                    "description": "ServerTiming adds a Server-Timing header with handler and store\ndurations (SERVER_TIMING). Off by default.",
+                    "type": "boolean"
+                },
+                "shutdown_timeout": {
+                    "description": "ShutdownTimeout is how long in-flight requests get to finish once\nSIGINT or SIGTERM arrives (SHUTDOWN_TIMEOUT, a Go duration); the\nconnections of those still running then are closed. Keep it below\nthe deployment's grace period. JSON shows it in nanoseconds.",
+                    "type": "integer",
+                    "example": 10000000000
+                },
+                "slow_request_threshold": {
+                    "description": "SlowRequestThreshold is the latency above which a request is logged\nat WARN (SLOW_REQUEST_THRESHOLD, a Go duration such as 500ms), which\nthe default LOG_LEVEL shows. Zero turns the log off. JSON shows it in\nnanoseconds.",
+                    "type": "integer",
+                    "example": 500000000
+                },
+                "storage": {
+                    "description": "Storage names the user store backend.",
+                    "type": "string",
+                    "example": "memory"
+                },
+                "strict_mode": {
+                    "description": "StrictMode rejects API requests without RequiredHeader with 400\n(STRICT_MODE). Off by default.",
+                    "type": "boolean"
+                },
+                "swagger_enabled": {
+                    "description": "SwaggerEnabled registers the /swagger UI and the raw spec at\n/openapi.json and /openapi.yaml (SWAGGER_ENABLED). Defaults to true, or\nfalse when Env is production.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.ExistsBatchRequest": {
+            "type": "object",
+            "required": [
+                "names"
+            ],
+            "properties": {
+                "names": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Agus",
+                        "Zed"
+                    ]
+                }
+            }
+        },
+        "main.Extremes": {
+            "type": "object",
+            "properties": {
+                "oldest": {
+                    "$ref": "#/definitions/store.User"
+                },
+                "youngest": {
+                    "$ref": "#/definitions/store.User"
+                }
+            }
+        },
+        "main.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "REQUIRED"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "name"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "name is required"
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "required"
+                }
+            }
+        },
+        "main.GetBatchRequest": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "main.HealthCheck": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "connection refused"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 0.012
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "threshold_ms": {
+                    "type": "number",
+                    "example": 100
+                }
+            }
+        },
+        "main.HealthReport": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/main.HealthCheck"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "main.ImportReport": {
+            "type": "object",
+            "properties": {
+                "invalid_rows": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ImportRowResult"
+                    }
+                },
+                "rows": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "valid_rows": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "main.ImportRowResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.FieldError"
+                    }
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.FieldError"
+                    }
+                }
+            }
+        },
+        "main.JSONSchema": {
+            "type": "object",
+            "properties": {
+                "$schema": {
+                    "type": "string"
+                },
+                "additionalProperties": {
+                    "type": "boolean"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/main.SchemaProperty"
+                    }
+                },
+                "required": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.MergeRequest": {
+            "type": "object",
+            "required": [
+                "source_id"
+            ],
+            "properties": {
+                "source_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "take_from_source": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "age"
+                    ]
+                }
+            }
+        },
+        "main.NameCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Agus"
+                }
+            }
+        },
+        "main.NameSuggestion": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
                 },
                 "name": {
+                    "type": "string",
+                    "example": "Agus"
+                }
+            }
+        },
+        "main.NotFoundDetail": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "message": {
+                    "type": "string",
+                    "example": "user not found"
+                }
+            }
+        },
+        "main.NotFoundResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/main.NotFoundDetail"
+                }
+            }
+        },
+        "main.Page-main_NameCount": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.NameCount"
+                    }
+                },
+                "filtered": {
+                    "description": "Filtered is set when the list was narrowed by a filter, telling \"no\nmatches\" apart from \"no users\" when data is empty.",
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "out_of_range": {
+                    "description": "OutOfRange is set when page is past the last page. Data is then\nempty; page 1 of an empty list is not out of range.",
+                    "type": "boolean"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.Page-store_Change": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Change"
+                    }
+                },
+                "filtered": {
+                    "description": "Filtered is set when the list was narrowed by a filter, telling \"no\nmatches\" apart from \"no users\" when data is empty.",
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "out_of_range": {
+                    "description": "OutOfRange is set when page is past the last page. Data is then\nempty; page 1 of an empty list is not out of range.",
+                    "type": "boolean"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.Page-store_User": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.User"
+                    }
+                },
+                "filtered": {
+                    "description": "Filtered is set when the list was narrowed by a filter, telling \"no\nmatches\" apart from \"no users\" when data is empty.",
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "out_of_range": {
+                    "description": "OutOfRange is set when page is past the last page. Data is then\nempty; page 1 of an empty list is not out of range.",
+                    "type": "boolean"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.PaginationCapabilities": {
+            "type": "object",
+            "properties": {
+                "default_limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "max_limit": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "modes": {
+                    "description": "Modes are \"page\" (page and limit parameters) and \"range\" (a Range:\nitems= header on GET /users).",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "page",
+                        "range"
+                    ]
+                }
+            }
+        },
+        "main.SchemaProperty": {
+            "type": "object",
+            "properties": {
+                "default": {},
+                "maxLength": {
+                    "type": "integer"
+                },
+                "maximum": {
+                    "type": "integer"
+                },
+                "minLength": {
+                    "type": "integer"
+                },
+                "minimum": {
+                    "type": "integer"
+                },
+                "type": {
                     "type": "string"
                 }
             }
+        },
+        "main.SearchRequest": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "max_age": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 30
+                },
+                "min_age": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 18
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ag"
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "sort": {
+                    "type": "string",
+                    "example": "age,-name"
+                }
+            }
+        },
+        "main.TenantHeaderCapability": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "X-Tenant-ID"
+                },
+                "required": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "main.UserComparison": {
+            "type": "object",
+            "properties": {
+                "a": {
+                    "$ref": "#/definitions/store.User"
+                },
+                "b": {
+                    "$ref": "#/definitions/store.User"
+                },
+                "diff": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "age": "same",
+                        "name": "different"
+                    }
+                }
+            }
+        },
+        "main.UserFilter": {
+            "type": "object",
+            "properties": {
+                "max_age": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 30
+                },
+                "min_age": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 18
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ag"
+                }
+            }
+        },
+        "main.UserResponse": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "age": {
+                    "description": "Age must be sent; 0 is a valid age.",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "created_at": {
+                    "description": "CreatedAt and UpdatedAt are maintained by the server and ignored on\ninput. UpdatedAt equals CreatedAt until the user is first modified.",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "display_name": {
+                    "description": "DisplayName is Name title-cased for display, when DISPLAY_NAMES is\non. It is maintained by the server and ignored on input; Name keeps\nthe name as entered.",
+                    "type": "string",
+                    "example": "Ludwig van Beethoven"
+                },
+                "field_updated_at": {
+                    "description": "FieldUpdatedAt records, per JSON field name, when that field last\nchanged value. It is maintained by the server and ignored on input.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.FieldError"
+                    }
+                }
+            }
+        },
+        "main.ValidationResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.FieldError"
+                    }
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "warnings": {
+                    "description": "Warnings are the soft checks a valid payload still raised.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.FieldError"
+                    }
+                }
+            }
+        },
+        "main.VersionInfo": {
+            "type": "object",
+            "properties": {
+                "build_date": {
+                    "type": "string",
+                    "example": "2026-01-02T15:04:05Z"
+                },
+                "commit": {
+                    "type": "string",
+                    "example": "d72be01"
+                },
+                "go_version": {
+                    "type": "string",
+                    "example": "go1.24.2"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "v1.2.0"
+                }
+            }
+        },
+        "store.Anomaly": {
+            "type": "object",
+            "properties": {
+                "fixed": {
+                    "description": "Fixed reports whether Repair corrected the problem. Only duplicate\nIDs are fixed; everything else is flagged for a human to resolve.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "description": "ID is the affected user's ID as found.",
+                    "type": "integer",
+                    "example": 2
+                },
+                "new_id": {
+                    "description": "NewID is the ID a duplicate was moved to.",
+                    "type": "integer",
+                    "example": 4
+                },
+                "problem": {
+                    "type": "string",
+                    "example": "duplicate id"
+                }
+            }
+        },
+        "store.Change": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ],
+                    "example": "update"
+                },
+                "at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "seq": {
+                    "description": "Seq numbers the store's changes from 1, without gaps, in the order\nthey were committed.",
+                    "type": "integer",
+                    "example": 7
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "store.RepairReport": {
+            "type": "object",
+            "properties": {
+                "anomalies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Anomaly"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "scanned": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "store.User": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "age": {
+                    "description": "Age must be sent; 0 is a valid age.",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "created_at": {
+                    "description": "CreatedAt and UpdatedAt are maintained by the server and ignored on\ninput. UpdatedAt equals CreatedAt until the user is first modified.",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "display_name": {
+                    "description": "DisplayName is Name title-cased for display, when DISPLAY_NAMES is\non. It is maintained by the server and ignored on input; Name keeps\nthe name as entered.",
+                    "type": "string",
+                    "example": "Ludwig van Beethoven"
+                },
+                "field_updated_at": {
+                    "description": "FieldUpdatedAt records, per JSON field name, when that field last\nchanged value. It is maintained by the server and ignored on input.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "store.UserPatch": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 16
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1,
+                    "example": "Agus"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Admin token, sent as \"Bearer \u003cADMIN_TOKEN\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
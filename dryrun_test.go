@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDryRunStoresNothing(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users?dry_run=true", `{"name":"Dewi","age":31}`)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decode[UserResponse](t, rec); got.ID != 4 || got.Name != "Dewi" {
+		t.Errorf("preview %s", rec.Body)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users/4", ""), http.StatusNotFound)
+
+	expectStatus(t, tc.Do(http.MethodPut, "/users/1?dry_run=1", `{"name":"Agustina","age":40}`), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1?dry_run=true", `{"name":"Agustina"}`), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodDelete, "/users/1?dry_run=true", ""), http.StatusOK)
+	if u := tc.GetUser(1); u.Name != "Agus" {
+		t.Errorf("user 1 changed by a dry run: %+v", u)
+	}
+
+	// a preview still fails the way the real write would
+	expectStatus(t, tc.Do(http.MethodPost, "/users?dry_run=true", `{"name":"agus","age":20}`), http.StatusConflict)
+	expectStatus(t, tc.Do(http.MethodPost, "/users?dry_run=maybe", `{"name":"Dewi","age":31}`), http.StatusBadRequest)
+}
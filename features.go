@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// features are the optional endpoints that DISABLED_FEATURES can switch
+// off, by flag name.
+var features = map[string]string{
+	"html":    "GET /users.html",
+	"bulk":    "POST /users/bulk-update and POST /users/bulk-set",
+	"merge":   "POST /users/:id/merge and GET /users/compare",
+	"clone":   "POST /users/:id/clone",
+	"similar": "GET /users/:id/similar",
+	"schema":  "GET /users/:id/json-schema",
+	"next_id": "GET /users/next-id",
+	"reports": "GET /users/name-counts and GET /users/extremes",
+}
+
+// checkFeatures rejects flag names that do not appear in features.
+func checkFeatures(names []string) error {
+	for _, name := range names {
+		if _, ok := features[name]; !ok {
+			return fmt.Errorf("DISABLED_FEATURES: unknown feature %q", name)
+		}
+	}
+	return nil
+}
+
+// featureEnabled reports whether the named feature is switched on.
+func featureEnabled(name string) bool {
+	for _, disabled := range cfg().DisabledFeatures {
+		if disabled == name {
+			return false
+		}
+	}
+	return true
+}
+
+// requireFeature answers the routes of a disabled feature with 404, as if
+// they did not exist. The routes stay registered so their paths are not
+// picked up by a broader route such as /users/:id instead.
+func requireFeature(name string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !featureEnabled(name) {
+				return c.JSON(http.StatusNotFound, echo.Map{"error": "Not found"})
+			}
+			return next(c)
+		}
+	}
+}
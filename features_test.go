@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDisabledFeatures(t *testing.T) {
+	t.Setenv("DISABLED_FEATURES", "clone,reports,next_id")
+	tc := newTestClient(t, nil)
+
+	expectStatus(t, tc.Do(http.MethodPost, "/users/1/clone", ""), http.StatusNotFound)
+	expectStatus(t, tc.Do(http.MethodGet, "/users/name-counts", ""), http.StatusNotFound)
+	expectStatus(t, tc.Do(http.MethodGet, "/users/extremes", ""), http.StatusNotFound)
+	// the path is not taken for a user ID instead, which would be a 400
+	rec := tc.Do(http.MethodGet, "/users/next-id", "")
+	expectStatus(t, rec, http.StatusNotFound)
+	if body := decode[map[string]string](t, rec); body["error"] != "Not found" {
+		t.Errorf("body %s", rec.Body)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users/1/similar", ""), http.StatusOK)
+	// nothing was cloned
+	expectStatus(t, tc.Do(http.MethodGet, "/users/4", ""), http.StatusNotFound)
+}
+
+func TestUnknownFeatureRejected(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("DISABLED_FEATURES", "clone,teleport")
+	if _, err := loadConfig(); err == nil {
+		t.Error("unknown feature accepted")
+	}
+}
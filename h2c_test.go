@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// listen starts tc's server on a free local port the way main does and
+// returns its base URL.
+func listen(t *testing.T, tc *testClient) string {
+	t.Helper()
+	tc.e.HideBanner, tc.e.HidePort = true, true
+	start := starter(tc.e, cfg(), "127.0.0.1:0")
+	go func() { _ = start() }()
+	t.Cleanup(func() { _ = tc.e.Close() })
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if addr := tc.e.ListenerAddr(); addr != nil {
+			return "http://" + addr.String()
+		}
+	}
+	t.Fatal("server did not start")
+	return ""
+}
+
+// h2cClient speaks HTTP/2 without TLS, with prior knowledge.
+var h2cClient = &http.Client{Transport: &http2.Transport{
+	AllowHTTP: true,
+	DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	},
+}}
+
+func TestH2C(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.H2C = true
+	})
+	base := listen(t, tc)
+
+	res, err := h2cClient.Get(base + "/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || res.ProtoMajor != 2 {
+		t.Errorf("h2c GET /users: %s over %s", res.Status, res.Proto)
+	}
+
+	// HTTP/1.1 clients are still served
+	res, err = http.Get(base + "/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || res.ProtoMajor != 1 {
+		t.Errorf("GET /users: %s over %s", res.Status, res.Proto)
+	}
+}
+
+func TestH2COff(t *testing.T) {
+	tc := newTestClient(t, nil)
+	base := listen(t, tc)
+
+	if res, err := h2cClient.Get(base + "/users"); err == nil {
+		res.Body.Close()
+		t.Errorf("h2c served while off: %s over %s", res.Status, res.Proto)
+	}
+}
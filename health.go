@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultHealthStoreThreshold applies when no configuration was loaded.
+const defaultHealthStoreThreshold = 100 * time.Millisecond
+
+// HealthCheck is the outcome of one probe in a HealthReport. Status is
+// "ok", "degraded" when too slow, or "failed" with the Error.
+type HealthCheck struct {
+	Status      string  `json:"status" example:"ok"`
+	LatencyMS   float64 `json:"latency_ms" example:"0.012"`
+	ThresholdMS float64 `json:"threshold_ms" example:"100"`
+	Error       string  `json:"error,omitempty" example:"connection refused"`
+}
+
+// HealthReport is the body of GET /healthz/detailed. Status is "ok" or
+// "degraded".
+type HealthReport struct {
+	Status string                 `json:"status" example:"ok"`
+	Checks map[string]HealthCheck `json:"checks"`
+}
+
+// GetHealth godoc
+// @Summary      Liveness
+// @Description  Reports that the process is up
+// @Tags         meta
+// @Produce      json
+// @Success      200  {object}  map[string]string
+// @Router       /healthz [get]
+func GetHealth(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"status": "ok"})
+}
+
+// Lister is what the detailed health check needs of a user store: a full
+// listing, which may fail or hang in a store that is not healthy.
+type Lister interface {
+	List() ([]store.User, error)
+}
+
+// listerFunc adapts a function to Lister.
+type listerFunc func() ([]store.User, error)
+
+func (f listerFunc) List() ([]store.User, error) { return f() }
+
+// defaultTenantLister lists the default tenant's store, which cannot fail.
+var defaultTenantLister = listerFunc(func() ([]store.User, error) {
+	return tenants.Default().List(), nil
+})
+
+// GetHealthDetailed godoc
+// @Summary      Health with store latency
+// @Description  Times a full listing of the default tenant's store. A listing that fails reports the store as failed, and one slower than HEALTH_STORE_THRESHOLD, or still running at it, as degraded; either way with 503.
+// @Tags         meta
+// @Produce      json
+// @Success      200  {object}  HealthReport
+// @Failure      503  {object}  HealthReport
+// @Router       /healthz/detailed [get]
+func GetHealthDetailed(users Lister) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		threshold := cfg().HealthStoreThreshold
+		if threshold <= 0 {
+			threshold = defaultHealthStoreThreshold
+		}
+
+		check := probeStore(users, threshold)
+		report := HealthReport{Status: "ok", Checks: map[string]HealthCheck{"store": check}}
+		status := http.StatusOK
+		if check.Status != "ok" {
+			report.Status = "degraded"
+			status = http.StatusServiceUnavailable
+		}
+		return c.JSON(status, report)
+	}
+}
+
+// probeStore times one listing of users. It waits at most threshold: a
+// listing still running then is left to finish on its own and reported
+// degraded, like a slow one.
+func probeStore(users Lister, threshold time.Duration) HealthCheck {
+	check := HealthCheck{Status: "ok", ThresholdMS: millis(threshold)}
+
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		_, err := users.List()
+		done <- err
+	}()
+	timer := time.NewTimer(threshold)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			check.Status = "failed"
+			check.Error = err.Error()
+		}
+	case <-timer.C:
+	}
+
+	elapsed := time.Since(start)
+	check.LatencyMS = millis(elapsed)
+	if check.Status == "ok" && elapsed > threshold {
+		check.Status = "degraded"
+	}
+	return check
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-echo/store"
+)
+
+func TestHealthDetailed(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.HealthStoreThreshold = time.Hour
+	})
+
+	rec := tc.Do(http.MethodGet, "/healthz/detailed", "")
+	expectStatus(t, rec, http.StatusOK)
+	report := decode[HealthReport](t, rec)
+	if check := report.Checks["store"]; report.Status != "ok" || check.Status != "ok" || check.ThresholdMS != millis(time.Hour) {
+		t.Errorf("real store: %s", rec.Body)
+	}
+}
+
+func TestHealthDetailedStubbedStore(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.HealthStoreThreshold = 20 * time.Millisecond
+	})
+	blocked := make(chan struct{})
+	t.Cleanup(func() { close(blocked) })
+
+	for _, tt := range []struct {
+		name   string
+		users  Lister
+		status int
+		check  string
+		err    string
+	}{
+		{"healthy", listerFunc(func() ([]store.User, error) { return nil, nil }), http.StatusOK, "ok", ""},
+		{"failing", listerFunc(func() ([]store.User, error) { return nil, errors.New("connection refused") }),
+			http.StatusServiceUnavailable, "failed", "connection refused"},
+		{"slow", listerFunc(func() ([]store.User, error) {
+			time.Sleep(40 * time.Millisecond)
+			return nil, nil
+		}), http.StatusServiceUnavailable, "degraded", ""},
+		{"blocked", listerFunc(func() ([]store.User, error) {
+			<-blocked
+			return nil, nil
+		}), http.StatusServiceUnavailable, "degraded", ""},
+	} {
+		rec := httptest.NewRecorder()
+		c := tc.e.NewContext(httptest.NewRequest(http.MethodGet, "/healthz/detailed", nil), rec)
+		start := time.Now()
+		if err := GetHealthDetailed(tt.users)(c); err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: the check took %v", tt.name, elapsed)
+		}
+
+		expectStatus(t, rec, tt.status)
+		report := decode[HealthReport](t, rec)
+		check := report.Checks["store"]
+		want := "ok"
+		if tt.status != http.StatusOK {
+			want = "degraded"
+		}
+		if report.Status != want || check.Status != tt.check || check.Error != tt.err {
+			t.Errorf("%s store: %s", tt.name, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// usersTable renders a Page of users for GET /users.html.
+var usersTable = template.Must(template.New("users").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Users</title></head>
+<body>
+<h1>Users</h1>
+<table>
+<thead><tr><th>ID</th><th>Name</th><th>Age</th><th>Created</th><th>Updated</th></tr></thead>
+<tbody>
+{{- range .Page.Data}}
+<tr><td>{{.ID}}</td><td>{{.Name}}</td><td>{{.Age}}</td><td>{{.CreatedAt.Format "2006-01-02 15:04:05"}}</td><td>{{.UpdatedAt.Format "2006-01-02 15:04:05"}}</td></tr>
+{{- else}}
+<tr><td colspan="5">No users</td></tr>
+{{- end}}
+</tbody>
+</table>
+<p>Page {{.Page.Page}} of {{.Page.TotalPages}} ({{.Page.Total}} users)
+{{- if .Prev}} <a rel="prev" href="{{.Prev}}">Previous</a>{{end}}
+{{- if .Next}} <a rel="next" href="{{.Next}}">Next</a>{{end}}</p>
+</body>
+</html>
+`))
+
+// GetUsersHTML godoc
+// @Summary      Browse users as HTML
+// @Description  Renders a page of users as an HTML table with previous/next links. Takes the same modified_since, sort, page and limit parameters as GET /users.
+// @Tags         users
+// @Produce      html
+// @Param        modified_since  query     string  false  "Only users created or updated at or after this RFC3339 time"
+// @Param        sort            query     string  false  "Comma-separated sort keys (id, name, age), prefix with - for descending (default DEFAULT_SORT)"
+// @Param        page            query     int     false  "Page number (default 1)"
+// @Param        limit           query     int     false  "Page size (default DEFAULT_PAGE_LIMIT, max MAX_PAGE_LIMIT)"
+// @Success      200             {string}  string  "HTML page"
+// @Failure      400             {object}  map[string]string
+// @Router       /users.html [get]
+func GetUsersHTML(c echo.Context) error {
+	params, errs := bindListParams(c)
+	if len(errs) > 0 {
+		return listParamsError(c, errs)
+	}
+	matched := selectUsers(c, params)
+	page, limit := params.page, params.limit
+
+	data := struct {
+		Page       Page[store.User]
+		Prev, Next string
+	}{Page: paginate(matched, page, limit)}
+	if page > 1 {
+		data.Prev = pageLink(c, page-1)
+	}
+	if page < data.Page.TotalPages {
+		data.Next = pageLink(c, page+1)
+	}
+
+	var buf bytes.Buffer
+	if err := usersTable.Execute(&buf, data); err != nil {
+		return err
+	}
+	return c.HTMLBlob(http.StatusOK, buf.Bytes())
+}
+
+// pageLink is the current request's URL with page replaced.
+func pageLink(c echo.Context, page int) string {
+	q := c.Request().URL.Query()
+	q.Set("page", strconv.Itoa(page))
+	return c.Request().URL.Path + "?" + q.Encode()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestUsersHTML(t *testing.T) {
+	tc := newTestClient(t, nil)
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/3", `{"name":"<b>Caca</b>"}`), http.StatusOK)
+
+	rec := tc.Do(http.MethodGet, "/users.html?limit=2&sort=-age", "")
+	expectStatus(t, rec, http.StatusOK)
+	body := rec.Body.String()
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("Content-Type %q", rec.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{
+		"<tr><td>3</td><td>&lt;b&gt;Caca&lt;/b&gt;</td><td>29</td>",
+		"<tr><td>2</td><td>Bagus</td><td>25</td>",
+		`<a rel="next" href="/users.html?limit=2&amp;page=2&amp;sort=-age">Next</a>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("no %s in\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "Agus") || strings.Contains(body, `rel="prev"`) {
+		t.Errorf("page 1 shows user 1 or a previous link:\n%s", body)
+	}
+
+	rec = tc.Do(http.MethodGet, "/users.html?limit=2&sort=-age&page=2", "")
+	expectStatus(t, rec, http.StatusOK)
+	body = rec.Body.String()
+	if !strings.Contains(body, "<td>Agus</td>") || !strings.Contains(body, `rel="prev"`) || strings.Contains(body, `rel="next"`) {
+		t.Errorf("last page:\n%s", body)
+	}
+}
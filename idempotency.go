@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"container/heap"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// headerIdempotencyKey names the client-chosen key that makes a write safe
+// to retry.
+const headerIdempotencyKey = "Idempotency-Key"
+
+// idempotentResponse is a stored write response, kept for replay.
+type idempotentResponse struct {
+	id string
+	// fingerprint identifies the request the key was first used with.
+	fingerprint [sha256.Size]byte
+	// done is false while the first request is still being handled.
+	done    bool
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+	// index is the position in idempotencyCache.byExpiry.
+	index int
+}
+
+// idempotencyPendingTimeout is how long a key stays claimed by a request
+// that has not finished, after which a retry runs the write again. It
+// frees keys whose request never completed, e.g. after a panic.
+const idempotencyPendingTimeout = time.Minute
+
+// replayedHeaders are the response headers stored along with the body.
+var replayedHeaders = []string{echo.HeaderContentType, echo.HeaderLocation, "ETag", "Preference-Applied"}
+
+// idempotencyCache holds up to maxEntries responses by tenant, method, path
+// and key. byExpiry orders them for expiry and eviction, soonest first.
+type idempotencyCache struct {
+	mu         sync.Mutex
+	entries    map[string]*idempotentResponse
+	byExpiry   expiryHeap
+	maxEntries int
+}
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests that carry an
+// Idempotency-Key header safe to retry. The first response for a key is
+// stored for ttl and replayed, marked with Idempotent-Replayed: true, to
+// later requests with the same method, path and key. Reusing a key with a
+// different query or body gets 409, as does a retry that arrives while the
+// first request is still running. Server errors are not stored, so a retry
+// after one runs the write again. Requests without the header are passed
+// through. At most maxKeys responses are kept; past that the ones closest
+// to expiry are dropped early.
+func Idempotency(ttl time.Duration, maxKeys int) echo.MiddlewareFunc {
+	cache := &idempotencyCache{entries: map[string]*idempotentResponse{}, maxEntries: maxKeys}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := req.Header.Get(headerIdempotencyKey)
+			if key == "" || !isWrite(req.Method) {
+				return next(c)
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			h := sha256.New()
+			h.Write([]byte(req.URL.RawQuery))
+			h.Write([]byte{0})
+			h.Write(body)
+			var fingerprint [sha256.Size]byte
+			copy(fingerprint[:], h.Sum(nil))
+
+			// the path is the request's, not the route's, so /users/1 and
+			// /users/2 keep separate keys
+			id := tenantID(c) + "\x00" + req.Method + "\x00" + req.URL.Path + "\x00" + key
+			entry, fresh := cache.claim(id, fingerprint)
+			switch {
+			case !fresh && entry.fingerprint != fingerprint:
+				return c.JSON(http.StatusConflict, echo.Map{"error": "Idempotency-Key was already used with a different request"})
+			case !fresh && !entry.done:
+				return c.JSON(http.StatusConflict, echo.Map{"error": "A request with this Idempotency-Key is still in progress"})
+			case !fresh:
+				res := c.Response()
+				for name, values := range entry.header {
+					res.Header()[name] = values
+				}
+				res.Header().Set("Idempotent-Replayed", "true")
+				res.WriteHeader(entry.status)
+				_, err := res.Write(entry.body)
+				return err
+			}
+
+			res := c.Response()
+			orig := res.Writer
+			buf := &bufferedWriter{ResponseWriter: orig, status: http.StatusOK}
+			res.Writer = buf
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			res.Writer = orig
+
+			if buf.status >= 500 {
+				cache.release(entry)
+			} else {
+				header := http.Header{}
+				for _, name := range replayedHeaders {
+					if v := res.Header().Get(name); v != "" {
+						header.Set(name, v)
+					}
+				}
+				cache.complete(entry, buf.status, header, buf.body.Bytes(), time.Now().Add(ttl))
+			}
+
+			orig.WriteHeader(buf.status)
+			_, err = orig.Write(buf.body.Bytes())
+			return err
+		}
+	}
+}
+
+// isWrite reports whether method changes state.
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// claim returns a copy of the live entry for id and false, or records a
+// pending one with fingerprint and returns it and true. Expired entries are
+// dropped on the way, and the soonest to expire when the cache is full.
+func (ic *idempotencyCache) claim(id string, fingerprint [sha256.Size]byte) (*idempotentResponse, bool) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	now := time.Now()
+	for len(ic.byExpiry) > 0 && now.After(ic.byExpiry[0].expires) {
+		ic.remove(ic.byExpiry[0])
+	}
+
+	if e, ok := ic.entries[id]; ok {
+		// copy, so the caller can read it without the lock
+		stored := *e
+		return &stored, false
+	}
+	for len(ic.byExpiry) > 0 && len(ic.byExpiry) >= ic.maxEntries {
+		ic.remove(ic.byExpiry[0])
+	}
+	e := &idempotentResponse{id: id, fingerprint: fingerprint, expires: now.Add(idempotencyPendingTimeout)}
+	ic.entries[id] = e
+	heap.Push(&ic.byExpiry, e)
+	return e, true
+}
+
+// complete stores the response for the pending entry e, kept until
+// expires. It does nothing if e expired or was evicted meanwhile.
+func (ic *idempotencyCache) complete(e *idempotentResponse, status int, header http.Header, body []byte, expires time.Time) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	if ic.entries[e.id] != e {
+		return
+	}
+	e.done = true
+	e.status = status
+	e.header = header
+	e.body = bytes.Clone(body)
+	e.expires = expires
+	heap.Fix(&ic.byExpiry, e.index)
+}
+
+// release forgets the pending entry e, so the key can be retried.
+func (ic *idempotencyCache) release(e *idempotentResponse) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	if ic.entries[e.id] == e {
+		ic.remove(e)
+	}
+}
+
+// remove drops e. Callers must hold mu.
+func (ic *idempotencyCache) remove(e *idempotentResponse) {
+	delete(ic.entries, e.id)
+	heap.Remove(&ic.byExpiry, e.index)
+}
+
+// expiryHeap is a min-heap of entries by expiry, for container/heap.
+type expiryHeap []*idempotentResponse
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expires.Before(h[j].expires) }
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap) Push(x any) {
+	e := x.(*idempotentResponse)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
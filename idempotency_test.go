@@ -0,0 +1,97 @@
+package main
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyReplay(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	first := tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":31}`, "Idempotency-Key", "k1")
+	expectStatus(t, first, http.StatusCreated)
+	again := tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":31}`, "Idempotency-Key", "k1")
+	expectStatus(t, again, http.StatusCreated)
+	if again.Header().Get("Idempotent-Replayed") != "true" || again.Body.String() != first.Body.String() {
+		t.Errorf("retry was not replayed: %s", again.Body)
+	}
+}
+
+func TestIdempotencyCacheCap(t *testing.T) {
+	cache := &idempotencyCache{entries: map[string]*idempotentResponse{}, maxEntries: 2}
+	var fp [sha256.Size]byte
+
+	for _, id := range []string{"a", "b", "c"} {
+		e, fresh := cache.claim(id, fp)
+		if !fresh {
+			t.Fatalf("claim(%q) not fresh", id)
+		}
+		cache.complete(e, http.StatusCreated, nil, nil, time.Now().Add(time.Hour))
+	}
+	if len(cache.entries) != 2 {
+		t.Fatalf("%d entries, want 2", len(cache.entries))
+	}
+	if _, ok := cache.entries["a"]; ok {
+		t.Error("oldest entry was not evicted")
+	}
+}
+
+func TestIdempotencyPendingExpires(t *testing.T) {
+	cache := &idempotencyCache{entries: map[string]*idempotentResponse{}, maxEntries: 10}
+	var fp [sha256.Size]byte
+
+	stuck, _ := cache.claim("a", fp)
+	if _, fresh := cache.claim("a", fp); fresh {
+		t.Fatal("pending key claimed twice")
+	}
+
+	// as if the first request never finished
+	stuck.expires = time.Now().Add(-time.Second)
+	retry, fresh := cache.claim("a", fp)
+	if !fresh {
+		t.Fatal("expired pending key still claimed")
+	}
+
+	// the stuck request finishing late must not overwrite the retry's entry
+	cache.complete(stuck, http.StatusCreated, nil, []byte("late"), time.Now().Add(time.Hour))
+	if retry.done {
+		t.Error("late completion was stored on the retry's entry")
+	}
+}
+
+func TestIdempotentPutAndDelete(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	put := tc.Do(http.MethodPut, "/users/1", `{"name":"Agustina","age":16}`, "Idempotency-Key", "put-1")
+	expectStatus(t, put, http.StatusOK)
+	// a retry after someone else's change still gets the first answer
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `{"age":17}`), http.StatusOK)
+	again := tc.Do(http.MethodPut, "/users/1", `{"name":"Agustina","age":16}`, "Idempotency-Key", "put-1")
+	if again.Header().Get("Idempotent-Replayed") != "true" || again.Body.String() != put.Body.String() {
+		t.Errorf("PUT retry was not replayed: %s", again.Body)
+	}
+	if u := tc.GetUser(1); u.Age != 17 {
+		t.Errorf("replay wrote age %d", u.Age)
+	}
+
+	del := tc.Do(http.MethodDelete, "/users/2", "", "Idempotency-Key", "del-1")
+	expectStatus(t, del, http.StatusOK)
+	again = tc.Do(http.MethodDelete, "/users/2", "", "Idempotency-Key", "del-1")
+	expectStatus(t, again, http.StatusOK)
+	if again.Header().Get("Idempotent-Replayed") != "true" || again.Body.String() != del.Body.String() {
+		t.Errorf("DELETE retry was not replayed: %s", again.Body)
+	}
+	// without the key the second delete is a real one
+	expectStatus(t, tc.Do(http.MethodDelete, "/users/2", ""), http.StatusNotFound)
+}
+
+func TestIdempotencyKeyReused(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	expectStatus(t, tc.Do(http.MethodPut, "/users/1", `{"name":"Agustina","age":16}`, "Idempotency-Key", "k"), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodPut, "/users/1", `{"name":"Agustina","age":18}`, "Idempotency-Key", "k"), http.StatusConflict)
+	// keys are scoped to method and path
+	expectStatus(t, tc.Do(http.MethodDelete, "/users/3", "", "Idempotency-Key", "k"), http.StatusOK)
+}
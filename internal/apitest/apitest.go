@@ -0,0 +1,63 @@
+// Package apitest drives an HTTP handler in-process for tests: requests go
+// straight to the handler through httptest, without a listener.
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Client sends requests to one handler and records the responses.
+type Client struct {
+	t       testing.TB
+	handler http.Handler
+	// Header is sent with every request, e.g. a tenant header.
+	Header http.Header
+}
+
+// New returns a Client for h that reports to t.
+func New(t testing.TB, h http.Handler) *Client {
+	return &Client{t: t, handler: h, Header: http.Header{}}
+}
+
+// Do sends a request and returns the recorded response. A non-empty body
+// is sent as JSON unless header says otherwise; header lists name, value
+// pairs added to the request.
+func (c *Client) Do(method, target, body string, header ...string) *httptest.ResponseRecorder {
+	c.t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for name, values := range c.Header {
+		req.Header[name] = values
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// Decode unmarshals the body of rec into a T, failing t if it does not
+// decode.
+func Decode[T any](t testing.TB, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decoding %s: %v", rec.Body, err)
+	}
+	return v
+}
+
+// ExpectStatus fails t unless rec has the given status.
+func ExpectStatus(t testing.TB, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status %d, want %d; body %s", rec.Code, status, rec.Body)
+	}
+}
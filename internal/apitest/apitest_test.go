@@ -0,0 +1,33 @@
+package apitest
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestClientDo(t *testing.T) {
+	c := New(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"tenant":"`+r.Header.Get("X-Tenant-ID")+`","type":"`+r.Header.Get("Content-Type")+`","body":`+string(body)+`}`)
+	}))
+	c.Header.Set("X-Tenant-ID", "acme")
+
+	rec := c.Do(http.MethodPost, "/users", `{"name":"Dewi"}`)
+	ExpectStatus(t, rec, http.StatusCreated)
+	got := Decode[struct {
+		Tenant, Type string
+		Body         map[string]string
+	}](t, rec)
+	if got.Tenant != "acme" || got.Type != "application/json" || got.Body["name"] != "Dewi" {
+		t.Errorf("handler saw %+v", got)
+	}
+
+	// header pairs win over the defaults
+	rec = c.Do(http.MethodPost, "/users", `"x"`, "Content-Type", "text/csv", "X-Tenant-ID", "other")
+	if got := Decode[map[string]any](t, rec); got["type"] != "text/csv" || got["tenant"] != "other" {
+		t.Errorf("handler saw %v", got)
+	}
+}
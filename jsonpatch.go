@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// mimeJSONPatch is the RFC 6902 media type accepted by PATCH /users/:id.
+const mimeJSONPatch = "application/json-patch+json"
+
+// JSONPatchOp is one operation of an RFC 6902 document.
+type JSONPatchOp struct {
+	Op    string          `json:"op" example:"replace"`
+	Path  string          `json:"path" example:"/name"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// immutablePaths are user fields maintained by the server, which a patch
+// may not touch.
+var immutablePaths = map[string]bool{
+	"/id":               true,
+	"/created_at":       true,
+	"/updated_at":       true,
+	"/field_updated_at": true,
+	"/display_name":     true,
+}
+
+// requiredPaths are the patchable fields that cannot be removed.
+var requiredPaths = map[string]string{
+	"/name": "name",
+	"/age":  "age",
+}
+
+// isJSONPatch reports whether the request body is a JSON Patch document.
+func isJSONPatch(c echo.Context) bool {
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	return err == nil && mediaType == mimeJSONPatch
+}
+
+// jsonPatchError is a document that cannot be applied, with the status to
+// answer it with.
+type jsonPatchError struct {
+	status  int
+	message string
+}
+
+func (e *jsonPatchError) Error() string {
+	return e.message
+}
+
+func badPatch(format string, args ...interface{}) *jsonPatchError {
+	return &jsonPatchError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+// bindJSONPatch reads the request body as a JSON Patch document and folds
+// its operations, in order, into the equivalent UserPatch. Only add, replace
+// and remove are supported; add and replace are the same thing on a user's
+// fixed set of fields.
+func bindJSONPatch(c echo.Context) (store.UserPatch, *jsonPatchError) {
+	var ops []JSONPatchOp
+	if err := checkJSONShape(c.Request(), &ops); err != nil {
+		return store.UserPatch{}, badPatch("%s", err)
+	}
+	if err := json.NewDecoder(c.Request().Body).Decode(&ops); err != nil {
+		return store.UserPatch{}, badPatch("Invalid JSON Patch document")
+	}
+
+	var patch store.UserPatch
+	for i, op := range ops {
+		if immutablePaths[op.Path] {
+			return store.UserPatch{}, badPatch("operation %d: %s is read-only", i, op.Path)
+		}
+		field, ok := requiredPaths[op.Path]
+		if !ok {
+			return store.UserPatch{}, badPatch("operation %d: unknown path %q", i, op.Path)
+		}
+
+		switch op.Op {
+		case "add", "replace":
+			if err := applyPatchValue(&patch, op); err != nil {
+				return store.UserPatch{}, badPatch("operation %d: %s", i, err)
+			}
+		case "remove":
+			return store.UserPatch{}, &jsonPatchError{
+				status:  http.StatusUnprocessableEntity,
+				message: field + " is required",
+			}
+		default:
+			return store.UserPatch{}, badPatch("operation %d: unsupported op %q", i, op.Op)
+		}
+	}
+	return patch, nil
+}
+
+// applyPatchValue decodes op.Value into the field op.Path names.
+func applyPatchValue(patch *store.UserPatch, op JSONPatchOp) error {
+	if len(op.Value) == 0 {
+		return fmt.Errorf("%s needs a value", op.Op)
+	}
+
+	switch op.Path {
+	case "/name":
+		var name string
+		if err := json.Unmarshal(op.Value, &name); err != nil {
+			return fmt.Errorf("value for /name must be a string")
+		}
+		patch.Name = &name
+	case "/age":
+		age, err := store.ParseAge(op.Value)
+		if err != nil {
+			return err
+		}
+		patch.Age = &age
+	}
+	return nil
+}
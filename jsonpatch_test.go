@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestJSONPatch(t *testing.T) {
+	tc := newTestClient(t, nil)
+	patch := func(doc string) int {
+		t.Helper()
+		return tc.Do(http.MethodPatch, "/users/1", doc, "Content-Type", mimeJSONPatch).Code
+	}
+
+	if code := patch(`[{"op":"replace","path":"/name","value":"Agustina"},{"op":"add","path":"/age","value":16}]`); code != http.StatusOK {
+		t.Fatalf("replace: status %d", code)
+	}
+	if u := tc.GetUser(1); u.Name != "Agustina" || u.Age != 16 {
+		t.Errorf("after patch %+v", u)
+	}
+
+	for _, tt := range []struct {
+		doc    string
+		status int
+	}{
+		{`[{"op":"replace","path":"/id","value":9}]`, http.StatusBadRequest},
+		{`[{"op":"replace","path":"/email","value":"a@b.c"}]`, http.StatusBadRequest},
+		{`[{"op":"move","from":"/name","path":"/age"}]`, http.StatusBadRequest},
+		{`[{"op":"replace","path":"/name"}]`, http.StatusBadRequest},
+		{`{"op":"replace","path":"/name","value":"x"}`, http.StatusBadRequest},
+		{`[{"op":"remove","path":"/name"}]`, http.StatusUnprocessableEntity},
+		{`[{"op":"replace","path":"/age","value":-1}]`, http.StatusUnprocessableEntity},
+		{`[{"op":"replace","path":"/name","value":"bagus"}]`, http.StatusConflict},
+	} {
+		if code := patch(tt.doc); code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.doc, code, tt.status)
+		}
+	}
+	// a failed document changes nothing
+	if u := tc.GetUser(1); u.Name != "Agustina" || u.Age != 16 {
+		t.Errorf("after failed patches %+v", u)
+	}
+}
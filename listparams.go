@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// ListParams are the query parameters of the user listings, GET /users and
+// GET /users.html, as bound by bindListParams: page, limit, sort and
+// modified_since. Unset values are nil when left out.
+type ListParams struct {
+	Page          queryValue[int]       `query:"page" validate:"omitnil,min=1"`
+	Limit         queryValue[int]       `query:"limit" validate:"omitnil,page_limit"`
+	Sort          string                `query:"sort"`
+	ModifiedSince queryValue[time.Time] `query:"modified_since"`
+
+	// filled in by bindListParams from the fields above and the defaults
+	page, limit int
+	sortKeys    []sortKey
+}
+
+// queryValue is an optional integer or RFC 3339 time query parameter. echo's
+// binder stops at the first value that does not parse, so queryValue takes
+// any value and remembers whether it parsed; that way bindListParams can
+// report every bad parameter. Validation rules apply to Value.
+type queryValue[T int | time.Time] struct {
+	// Value is nil when the parameter is left out, empty or invalid.
+	Value *T
+	// Invalid is set when the parameter did not parse.
+	Invalid bool
+}
+
+// UnmarshalParam implements echo.BindUnmarshaler.
+func (q *queryValue[T]) UnmarshalParam(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	var v T
+	var err error
+	switch p := any(&v).(type) {
+	case *int:
+		*p, err = strconv.Atoi(raw)
+	case *time.Time:
+		*p, err = time.Parse(time.RFC3339, raw)
+	}
+	if err != nil {
+		q.Invalid = true
+		return nil
+	}
+	q.Value = &v
+	return nil
+}
+
+// validatedValue is what validation rules see of q, see newValidator.
+func (q queryValue[T]) validatedValue() any {
+	return q.Value
+}
+
+// listParamErrors are the messages for query values that do not parse.
+var listParamErrors = map[string]string{
+	"page":           "must be an integer",
+	"limit":          "must be an integer",
+	"modified_since": "must be an RFC3339 timestamp",
+}
+
+// bindListParams binds and checks the listing query of c. Defaults apply to
+// what is left out: page 1, DEFAULT_PAGE_LIMIT and DEFAULT_SORT. Problems
+// are reported per parameter, all of them, to be answered with
+// listParamsError.
+func bindListParams(c echo.Context) (ListParams, map[string]string) {
+	var p ListParams
+	errs := map[string]string{}
+
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &p); err != nil {
+		errs["query"] = "could not be parsed"
+		return p, errs
+	}
+	for name, invalid := range map[string]bool{
+		"page":           p.Page.Invalid,
+		"limit":          p.Limit.Invalid,
+		"modified_since": p.ModifiedSince.Invalid,
+	} {
+		if invalid {
+			errs[name] = listParamErrors[name]
+		}
+	}
+	if err := validateRequest(c, &p); err != nil {
+		var verrs validator.ValidationErrors
+		if !errors.As(err, &verrs) {
+			return p, map[string]string{"query": "could not be parsed"}
+		}
+		for _, fe := range verrs {
+			errs[fe.Field()] = ruleMessage(fe)
+		}
+	}
+
+	p.page, p.limit = 1, cfg().DefaultPageLimit
+	if p.Page.Value != nil {
+		p.page = *p.Page.Value
+	}
+	if p.Limit.Value != nil {
+		p.limit = *p.Limit.Value
+	}
+
+	sortParam := p.Sort
+	if sortParam == "" {
+		sortParam = cfg().DefaultSort
+	}
+	keys, err := parseSort(sortParam)
+	if err != nil {
+		errs["sort"] = err.Error()
+	}
+	p.sortKeys = keys
+
+	return p, errs
+}
+
+// filtered reports whether the parameters narrow the listing.
+func (p *ListParams) filtered() bool {
+	return p.ModifiedSince.Value != nil
+}
+
+// listParamsError writes the 400 for a listing query bindListParams
+// rejected.
+func listParamsError(c echo.Context, errs map[string]string) error {
+	return c.JSON(http.StatusBadRequest, echo.Map{
+		"error":  "Invalid query parameters",
+		"fields": errs,
+	})
+}
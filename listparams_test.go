@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListParamsReportsEveryField(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/users?page=abc&limit=0&modified_since=yesterday", "")
+	expectStatus(t, rec, http.StatusBadRequest)
+	body := decode[struct {
+		Fields map[string]string `json:"fields"`
+	}](t, rec)
+	for field, msg := range map[string]string{
+		"page":           "must be an integer",
+		"limit":          "must be between 1 and 100",
+		"modified_since": "must be an RFC3339 timestamp",
+	} {
+		if body.Fields[field] != msg {
+			t.Errorf("%s: %q, want %q in %s", field, body.Fields[field], msg, rec.Body)
+		}
+	}
+
+	rec = tc.Do(http.MethodGet, "/users?page=0&limit=abc&sort=height", "")
+	expectStatus(t, rec, http.StatusBadRequest)
+	body = decode[struct {
+		Fields map[string]string `json:"fields"`
+	}](t, rec)
+	if body.Fields["page"] != "must be at least 1" || body.Fields["limit"] != "must be an integer" || body.Fields["sort"] == "" {
+		t.Errorf("fields %v", body.Fields)
+	}
+
+	// empty values are left out, as before
+	expectStatus(t, tc.Do(http.MethodGet, "/users?page=&limit=&modified_since=", ""), http.StatusOK)
+}
+
+func TestListParamsValid(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/users?page=1&limit=2&sort=-age&modified_since=2000-01-01T00:00:00Z", "")
+	expectStatus(t, rec, http.StatusOK)
+	page := decode[struct {
+		Limit int `json:"limit"`
+	}](t, rec)
+	if page.Limit != 2 {
+		t.Errorf("limit %d, want 2", page.Limit)
+	}
+}
+
+func TestBindListParamsPartial(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.DefaultPageLimit = 7
+		conf.DefaultSort = "name"
+	})
+	bind := func(query string) (ListParams, map[string]string) {
+		t.Helper()
+		c := tc.e.NewContext(httptest.NewRequest(http.MethodGet, "/users"+query, nil), httptest.NewRecorder())
+		// as ResolveTenant would
+		c.Set(tenantStoreKey, tenants.Default())
+		return bindListParams(c)
+	}
+
+	p, errs := bind("")
+	if len(errs) != 0 || p.Page.Value != nil || p.Limit.Value != nil || p.page != 1 || p.limit != 7 || p.filtered() {
+		t.Errorf("empty query: %+v, %v", p, errs)
+	}
+	if len(p.sortKeys) != 1 || p.sortKeys[0] != (sortKey{field: "name"}) {
+		t.Errorf("default sort %+v, want name", p.sortKeys)
+	}
+
+	p, errs = bind("?limit=3&sort=-age")
+	if len(errs) != 0 || p.page != 1 || p.limit != 3 || p.sortKeys[0] != (sortKey{field: "age", desc: true}) {
+		t.Errorf("limit and sort only: %+v, %v", p, errs)
+	}
+
+	p, errs = bind("?modified_since=2000-01-01T00:00:00Z")
+	if len(errs) != 0 || p.ModifiedSince.Value == nil || !p.filtered() || p.limit != 7 {
+		t.Errorf("modified_since only: %+v, %v", p, errs)
+	}
+
+	// one bad value among good ones is reported alone
+	_, errs = bind("?page=2&limit=3&sort=height")
+	if len(errs) != 1 || errs["sort"] == "" {
+		t.Errorf("bad sort: %v", errs)
+	}
+}
@@ -1,14 +1,25 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	_ "go-echo/docs"
+	"go-echo/store"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/labstack/gommon/log"
 	echoSwagger "github.com/swaggo/echo-swagger"
+	"golang.org/x/net/http2"
+	"golang.org/x/sync/singleflight"
 )
 
 type CustomValidator struct {
@@ -19,91 +30,477 @@ func (cv *CustomValidator) Validate(i interface{}) error {
 	return cv.validator.Struct(i)
 }
 
-type User struct {
-	ID   int    `json:"id"`
-	Name string `json:"name" validate:"required"`
-	Age  int    `json:"age" validate:"required,min=0"`
+// NotFoundResponse is the body returned when a requested user does not exist.
+type NotFoundResponse struct {
+	Error NotFoundDetail `json:"error"`
 }
 
-var users = []User{
-	{ID: 1, Name: "Agus", Age: 15},
-	{ID: 2, Name: "Bagus", Age: 25},
-	{ID: 3, Name: "Caca", Age: 29},
+// NotFoundDetail carries the ID that was looked up so clients and logs can
+// tell exactly what was requested.
+type NotFoundDetail struct {
+	Message string `json:"message" example:"user not found"`
+	ID      int    `json:"id" example:"42"`
 }
 
+// userNotFound writes the structured 404 for the given user ID.
+func userNotFound(c echo.Context, id int) error {
+	return c.JSON(http.StatusNotFound, NotFoundResponse{
+		Error: NotFoundDetail{Message: "user not found", ID: id},
+	})
+}
+
+// isDryRun reports whether the request asked, via ?dry_run=true, for a write
+// to be validated and previewed without being committed.
+func isDryRun(c echo.Context) (bool, error) {
+	raw := c.QueryParam("dry_run")
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
+
+// invalidDryRun writes the 400 for an unparsable dry_run parameter.
+func invalidDryRun(c echo.Context) error {
+	return c.JSON(http.StatusBadRequest, echo.Map{"error": "dry_run must be a boolean"})
+}
+
+// returnPreference reads the return preference of an RFC 7240 Prefer
+// header: "minimal" or "representation", or "" when none was expressed or
+// its value is unknown.
+func returnPreference(c echo.Context) string {
+	for _, header := range c.Request().Header.Values("Prefer") {
+		for _, pref := range strings.Split(header, ",") {
+			token, _, _ := strings.Cut(strings.TrimSpace(pref), ";")
+			name, value, _ := strings.Cut(strings.TrimSpace(token), "=")
+			if !strings.EqualFold(strings.TrimSpace(name), "return") {
+				continue
+			}
+			switch value = strings.Trim(strings.TrimSpace(value), `"`); value {
+			case "minimal", "representation":
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// writeUser answers a write with u and status, honoring Prefer: with
+// return=minimal it sends 204 and only a Location header pointing at u. An
+// honored preference is confirmed in Preference-Applied. Dry runs always
+// get the representation, since nothing was stored for Location to name.
+func writeUser(c echo.Context, status int, u store.User) error {
+	pref := returnPreference(c)
+	if dryRun, _ := isDryRun(c); dryRun && pref == "minimal" {
+		pref = ""
+	}
+	if pref != "" {
+		c.Response().Header().Set("Preference-Applied", "return="+pref)
+	}
+	if pref == "minimal" {
+		c.Response().Header().Set(echo.HeaderLocation, c.Echo().Reverse(routeGetUser, u.ID))
+		return c.NoContent(http.StatusNoContent)
+	}
+	return c.JSON(status, withWarnings(u))
+}
+
+// clientIDRejected writes the 400 for a create body that carries an ID.
+func clientIDRejected(c echo.Context) error {
+	return c.JSON(http.StatusBadRequest, echo.Map{"error": "id must not be set, IDs are server-assigned"})
+}
+
+// routeGetUser names the single-user route for reverse routing.
+const routeGetUser = "get-user"
+
+// tenants holds the users of every tenant, set up by newServer.
+var tenants *store.Tenants
+
+// tenantID is the tenant a request acts for, named by the REQUIRED_HEADER
+// header (X-Tenant-ID by default). Requests without it use the default
+// tenant.
+func tenantID(c echo.Context) string {
+	return strings.TrimSpace(c.Request().Header.Get(cfg().RequiredHeader))
+}
+
+// tenantStoreKey is the context key under which ResolveTenant leaves the
+// request's store.
+const tenantStoreKey = "tenant-store"
+
+// ResolveTenant looks up the store of the request's tenant for usersFor.
+// An unknown tenant gets an empty store and only comes into existence with
+// its first user; past MAX_TENANTS that create gets 507.
+func ResolveTenant(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Set(tenantStoreKey, tenants.For(tenantID(c)))
+		return next(c)
+	}
+}
+
+// usersFor returns the store of the request's tenant, as resolved by
+// ResolveTenant.
+func usersFor(c echo.Context) *store.Memory {
+	return c.Get(tenantStoreKey).(*store.Memory)
+}
+
+// userLookups coalesces concurrent GetUserByID reads of the same user into
+// one store call.
+var userLookups singleflight.Group
+
+// getUser is the store read lookupUser shares, and lookupJoined is called
+// once a lookup has joined the shared read, before waiting on it. Tests
+// replace them to count the reads and to hold one open until all their
+// requests are parked on it.
+var (
+	getUser      = (*store.Memory).Get
+	lookupJoined = func() {}
+)
+
+// lookupUser is Get on the request's store, shared between concurrent
+// callers asking for the same tenant and ID. Callers must treat the result
+// as read-only, since they may all hold the same value.
+func lookupUser(c echo.Context, id int) (store.User, error) {
+	users := usersFor(c)
+	done := userLookups.DoChan(tenantID(c)+"/"+strconv.Itoa(id), func() (interface{}, error) {
+		return getUser(users, id)
+	})
+	lookupJoined()
+	res := <-done
+	if res.Err != nil {
+		return store.User{}, res.Err
+	}
+	return res.Val.(store.User), nil
+}
+
+// storeError writes the response for an error returned by users, mapping
+// each store sentinel to its status.
+func storeError(c echo.Context, err error) error {
+	var notFound *store.NotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		return userNotFound(c, notFound.ID)
+	case errors.Is(err, store.ErrNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "User not found"})
+	case errors.Is(err, store.ErrDuplicateName), errors.Is(err, store.ErrConflict):
+		return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+	case errors.Is(err, store.ErrFull):
+		return c.JSON(http.StatusInsufficientStorage, echo.Map{"error": "User limit reached"})
+	case errors.Is(err, store.ErrTooManyTenants):
+		return c.JSON(http.StatusInsufficientStorage, echo.Map{"error": "Tenant limit reached"})
+	default:
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Internal server error"})
+	}
+}
+
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 Admin token, sent as "Bearer <ADMIN_TOKEN>"
 func main() {
+	conf, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	e := newServer(conf)
+	serve(e, starter(e, conf, ":8080"), conf.ShutdownTimeout)
+}
+
+// starter returns the function that starts e listening on address, over
+// HTTP/1.1 or, with H2C, over HTTP/2 cleartext as well.
+func starter(e *echo.Echo, conf *Config, address string) func() error {
+	if conf.H2C {
+		// HTTP/2 without TLS for internal callers; HTTP/1.1 clients are
+		// still served on the same port
+		return func() error { return e.StartH2CServer(address, &http2.Server{}) }
+	}
+	return func() error { return e.Start(address) }
+}
+
+// newServer builds the application around conf with every route
+// registered, ready to Start or to drive in-process with httptest.
+func newServer(conf *Config) *echo.Echo {
 	e := echo.New()
+	applyConfig(e, conf)
 
-	e.Validator = &CustomValidator{validator: validator.New()}
+	seed := conf.Seed
+	if seed == nil {
+		seed = store.SeedUsers(time.Now().UTC())
+	}
+	var format func(string) string
+	if conf.DisplayNames {
+		format = displayName(conf.DisplayNameParticles)
+	}
+	tenants = store.NewTenants(seed, conf.MaxUsers, conf.MaxTenants, format)
+
+	e.Validator = &CustomValidator{validator: newValidator()}
+	e.JSONSerializer = jsonSerializer{escapeHTML: conf.JSONEscapeHTML}
 
-	e.GET("/swagger/*", echoSwagger.WrapHandler)
+	// first of all, so the shutdown report counts every request
+	e.Pre(CountInFlight)
+	e.Server.RegisterOnShutdown(startDraining)
+
+	// before routing, so over-long URLs get 414 whatever they address
+	if conf.MaxURLLength > 0 {
+		e.Pre(MaxURLLength(conf.MaxURLLength))
+	}
+
+	// The canonical form has no trailing slash. "/users/" is rewritten to
+	// "/users" before routing, without a redirect. The swagger UI is left
+	// alone since it serves its index under "/swagger/".
+	e.Pre(middleware.RemoveTrailingSlashWithConfig(middleware.TrailingSlashConfig{
+		Skipper: func(c echo.Context) bool {
+			return strings.HasPrefix(c.Request().URL.Path, "/swagger/")
+		},
+	}))
+
+	if conf.ForceHTTPS {
+		e.Pre(ForceHTTPS(conf.HSTSMaxAge))
+	}
+
+	// first, so everything after it sees the ID
+	e.Use(RequestID(conf.RequestIDHeader))
+
+	if conf.SlowRequestThreshold > 0 {
+		e.Use(SlowRequests(conf.SlowRequestThreshold))
+	}
+
+	if conf.RetryStormThreshold > 0 {
+		e.Use(RetryStorms(conf.RetryStormWindow, conf.RetryStormThreshold))
+	}
+
+	if conf.ServerTiming {
+		e.Use(ServerTiming)
+	}
+
+	if conf.Gzip {
+		e.Use(middleware.Gzip())
+	}
+
+	if len(conf.CORSAllowOrigins) > 0 {
+		e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+			AllowOrigins:     conf.CORSAllowOrigins,
+			AllowCredentials: conf.CORSAllowCredentials,
+			MaxAge:           conf.CORSMaxAge,
+		}))
+	}
+
+	// error bodies as problem+json, on request; inside Gzip so it sees the
+	// plain body
+	e.Use(ProblemDetails)
+
+	if conf.SwaggerEnabled {
+		e.GET("/swagger/*", echoSwagger.WrapHandler)
+		// the raw spec, for tooling that imports it
+		e.GET("/openapi.json", GetOpenAPIJSON)
+		e.GET("/openapi.yaml", GetOpenAPIYAML)
+	}
 
 	e.GET("/", func(c echo.Context) error {
 		return c.String(http.StatusOK, "Welcome to the User API")
 	})
-	e.GET("/users", GetUsers)
+	e.GET("/version", GetVersion)
+	e.GET("/capabilities", GetCapabilities)
+	e.GET("/healthz", GetHealth)
+	e.GET("/healthz/detailed", GetHealthDetailed(defaultTenantLister))
+
+	// middleware for the routes outside the API group that serve a
+	// tenant's users
+	var tenantScoped []echo.MiddlewareFunc
+	if conf.StrictMode {
+		tenantScoped = append(tenantScoped, RequireHeader(conf.RequiredHeader))
+	}
+	// only on routes that serve a tenant, which handlers rely on for
+	// usersFor
+	tenantScoped = append(tenantScoped, ResolveTenant)
+
+	// browsable listing for internal admin pages; outside the API group,
+	// which only speaks JSON
+	e.GET("/users.html", GetUsersHTML, append(tenantScoped, requireFeature("html"))...)
+
+	// every committed change, across users
+	changelog := e.Group("/changelog", tenantScoped...)
+	changelog.GET("", GetChangelog)
+	changelog.GET("/tail", TailChangelog)
+
+	// API routes only speak JSON, reject anything else up front
+	api := e.Group("/users", NegotiateAccept)
+	api.Use(tenantScoped...)
+	// inside any compression, so tags describe the uncompressed body
+	api.Use(ETag)
+	if conf.IdempotencyTTL > 0 {
+		api.Use(Idempotency(conf.IdempotencyTTL, conf.IdempotencyMaxKeys))
+	}
+	if conf.LogBodies {
+		api.Use(LogBodies(conf.LogBodyMaxBytes))
+	}
+
+	api.GET("", GetUsers)
 
-	// /users/:id
-	e.GET("/users/:id", GetUserByID)
+	// capability discovery; echo's own OPTIONS fallback never runs here
+	// because the group's catch-all 404 routes match first
+	api.OPTIONS("", AllowedMethods)
+	api.OPTIONS("/:id", AllowedMethods)
+
+	// /users/:id, named so handlers can build links to it with Reverse
+	api.GET("/:id", GetUserByID).Name = routeGetUser
+
+	// lookup by name, for callers that only know that
+	api.GET("/by-name/:name", GetUserByName)
 
 	// update user
-	e.PUT("/users/:id", UpdateUser)
+	api.PUT("/:id", UpdateUser)
+
+	// partially update user
+	api.PATCH("/:id", PatchUser)
 
 	// delete user
-	e.DELETE("/users/:id", DeleteUser)
+	api.DELETE("/:id", DeleteUser)
 
 	// insert user
-	e.POST("/users", CreateUser)
+	api.POST("", CreateUser)
+
+	// dry validation of a create payload
+	api.POST("/validate", ValidateUser)
+	// dry run of a CSV import, row by row
+	api.POST("/import/validate", ValidateImport)
+
+	// apply one change to every user matching a filter
+	api.POST("/bulk-update", BulkUpdateUsers, requireFeature("bulk"))
+
+	// set one field on a list of users
+	api.POST("/bulk-set", BulkSetUsers, requireFeature("bulk"))
 
-	e.Logger.Fatal(e.Start(":8080"))
+	// search with a JSON query body
+	api.POST("/search", SearchUsers)
+
+	// newest users first
+	api.GET("/recent", RecentUsers)
+
+	// name prefix suggestions for search boxes
+	api.GET("/autocomplete", AutocompleteNames)
+
+	// advisory ID of the next create
+	api.GET("/next-id", GetNextID, requireFeature("next_id"))
+
+	// check whether a name is already in use
+	api.GET("/exists", UserExists)
+	api.POST("/exists-batch", UsersExistBatch)
+
+	// several users at once, keyed by the requested IDs
+	api.POST("/get-batch", GetUsersBatch)
+
+	// fold a duplicate user into another
+	api.POST("/:id/merge", MergeUsers, requireFeature("merge"))
+
+	// side-by-side view of two users, ahead of a merge
+	api.GET("/compare", CompareUsers, requireFeature("merge"))
+
+	// copy a user under a new name
+	api.POST("/:id/clone", CloneUser, requireFeature("clone"))
+
+	// users closest in age to one user
+	api.GET("/:id/similar", SimilarUsers, requireFeature("similar"))
+
+	// editable fields of one user, for form builders
+	api.GET("/:id/json-schema", GetUserSchema, requireFeature("schema"))
+
+	// reports
+	api.GET("/name-counts", GetNameCounts, requireFeature("reports"))
+	api.GET("/extremes", GetExtremes, requireFeature("reports"))
+
+	registerAdminRoutes(e)
+
+	return e
+}
+
+// AllowedMethods answers OPTIONS with 204 and an Allow header listing the
+// methods registered for the matched route path.
+func AllowedMethods(c echo.Context) error {
+	methods := []string{}
+	for _, r := range c.Echo().Routes() {
+		if r.Path == c.Path() && r.Method != echo.RouteNotFound {
+			methods = append(methods, r.Method)
+		}
+	}
+	sort.Strings(methods)
+	c.Response().Header().Set(echo.HeaderAllow, strings.Join(methods, ", "))
+	return c.NoContent(http.StatusNoContent)
 }
 
 // CreateUser godoc
 // @Summary      Create a new user
-// @Description  Creates a new user with the provided details
+// @Description  Creates a new user with the provided details. IDs are server-assigned; a body with a non-zero id is rejected with 400.
+// @Description  With dry_run=true the user is validated and checked for conflicts and the would-be result is returned with 200, but nothing is stored.
+// @Description  Values that are allowed but unusual, such as an age above AGE_WARN_ABOVE, are reported in warnings without failing the request.
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Param        user  body      User  true  "User to create"
-// @Success      201   {object}  User
-// @Failure      400   {object}  map[string]string
+// @Param        user     body      store.User         true   "User to create"
+// @Param        dry_run  query     bool               false  "Preview without committing"
+// @Param        Prefer   header    string             false  "return=minimal for 204 without a body (ignored with dry_run), or return=representation (default)"
+// @Success      200      {object}  UserResponse       "Dry run preview"
+// @Success      201      {object}  UserResponse
+// @Success      204      "Prefer: return=minimal"
+// @Header       201      {string}  Location           "URL of the created user"
+// @Failure      400      {object}  map[string]string
+// @Failure      422      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      507      {object}  map[string]string  "MAX_USERS reached, or MAX_TENANTS by a new tenant's first user"
 // @Router       /users [post]
 func CreateUser(c echo.Context) error {
-	var newUser User
+	dryRun, err := isDryRun(c)
+	if err != nil {
+		return invalidDryRun(c)
+	}
+
+	var newUser store.User
 
 	if err := c.Bind(&newUser); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": bindErrorMessage(err)})
 	}
-
-	if err := c.Validate(&newUser); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	// IDs are server-assigned; refuse a client value rather than silently
+	// replacing it
+	if newUser.ID != 0 {
+		return clientIDRejected(c)
 	}
 
-	// Assign a new ID to the user (auto-increment based on current max ID)
-	maxID := 0
-	for _, u := range users {
-		if u.ID > maxID {
-			maxID = u.ID
-		}
+	if err := validateRequest(c, &newUser); err != nil {
+		return validationError(c, err)
 	}
-	newUser.ID = maxID + 1
 
-	users = append(users, newUser)
+	// unique_name ran before the store took its lock; Create re-checks so
+	// two concurrent creates can't both claim the name
+	done := timeStore(c)
+	created, err := usersFor(c).Create(newUser, dryRun)
+	done()
+	if err != nil {
+		return storeError(c, err)
+	}
+	if dryRun {
+		return writeUser(c, http.StatusOK, created)
+	}
 
-	return c.JSON(http.StatusCreated, newUser)
+	c.Response().Header().Set(echo.HeaderLocation, c.Echo().Reverse(routeGetUser, created.ID))
+	return writeUser(c, http.StatusCreated, created)
 }
 
 // UpdateUser godoc
 // @Summary      Update existing user
-// @Description  Updates user data for the given ID
+// @Description  Updates user data for the given ID.
+// @Description  With dry_run=true the update is validated and previewed but not stored.
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Param        id    path      int   true  "User ID"
-// @Param        user  body      User  true  "Updated user data"
-// @Success      200   {object}  User
-// @Failure      400   {object}  map[string]string
-// @Failure      404   {object}  map[string]string
+// @Param        id       path      int         true   "User ID"
+// @Param        user     body      store.User  true   "Updated user data"
+// @Param        dry_run  query     bool        false  "Preview without committing"
+// @Param        Prefer   header    string      false  "return=minimal for 204 without a body (ignored with dry_run), or return=representation (default)"
+// @Success      200      {object}  UserResponse
+// @Success      204      "Prefer: return=minimal"
+// @Failure      400      {object}  map[string]string
+// @Failure      422      {object}  map[string]string
+// @Failure      404      {object}  NotFoundResponse
+// @Failure      409      {object}  map[string]string
 // @Router       /users/{id} [put]
 func UpdateUser(c echo.Context) error {
 	id := c.Param("id")
@@ -113,35 +510,102 @@ func UpdateUser(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid user ID"})
 	}
 
-	var updated User
+	dryRun, err := isDryRun(c)
+	if err != nil {
+		return invalidDryRun(c)
+	}
+
+	var updated store.User
 	if err := c.Bind(&updated); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid input"})
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": bindErrorMessage(err)})
 	}
+	// ensure ID remains the path ID
+	updated.ID = idInt
 
-	if err := c.Validate(&updated); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	if err := validateRequest(c, &updated); err != nil {
+		return validationError(c, err)
+	}
+
+	// the store re-checks the name under its lock, see CreateUser
+	done := timeStore(c)
+	result, err := usersFor(c).Update(idInt, updated, dryRun)
+	done()
+	if err != nil {
+		return storeError(c, err)
+	}
+	return writeUser(c, http.StatusOK, result)
+}
+
+// PatchUser godoc
+// @Summary      Partially update user
+// @Description  Updates only the fields present in the body; omitted fields keep their current value.
+// @Description  An RFC 6902 document (array of {op, path, value}) is accepted instead with Content-Type application/json-patch+json; add, replace and remove are supported on /name and /age.
+// @Description  With dry_run=true the update is validated and previewed but not stored.
+// @Tags         users
+// @Accept       json
+// @Accept       application/json-patch+json
+// @Produce      json
+// @Param        id       path      int              true   "User ID"
+// @Param        user     body      store.UserPatch  true   "Fields to change"
+// @Param        dry_run  query     bool             false  "Preview without committing"
+// @Param        Prefer   header    string           false  "return=minimal for 204 without a body (ignored with dry_run), or return=representation (default)"
+// @Success      200      {object}  UserResponse
+// @Success      204      "Prefer: return=minimal"
+// @Failure      400      {object}  map[string]string
+// @Failure      422      {object}  map[string]string
+// @Failure      404      {object}  NotFoundResponse
+// @Failure      409      {object}  map[string]string
+// @Router       /users/{id} [patch]
+func PatchUser(c echo.Context) error {
+	id := c.Param("id")
+
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid user ID"})
+	}
+
+	dryRun, err := isDryRun(c)
+	if err != nil {
+		return invalidDryRun(c)
 	}
 
-	for i, u := range users {
-		if u.ID == idInt {
-			// ensure ID remains the path ID
-			updated.ID = idInt
-			users[i] = updated
-			return c.JSON(http.StatusOK, updated)
+	var patch store.UserPatch
+	if isJSONPatch(c) {
+		var perr *jsonPatchError
+		if patch, perr = bindJSONPatch(c); perr != nil {
+			return c.JSON(perr.status, echo.Map{"error": perr.message})
 		}
+	} else if err := c.Bind(&patch); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": bindErrorMessage(err)})
 	}
-	return c.JSON(http.StatusNotFound, echo.Map{"error": "User not found"})
+	patch.ID = idInt
+
+	if err := validateRequest(c, &patch); err != nil {
+		return validationError(c, err)
+	}
+
+	// the store re-checks the name under its lock, see CreateUser
+	done := timeStore(c)
+	updated, err := usersFor(c).Patch(idInt, patch, dryRun)
+	done()
+	if err != nil {
+		return storeError(c, err)
+	}
+	return writeUser(c, http.StatusOK, updated)
 }
 
 // DeleteUser godoc
 // @Summary      Delete user by ID
-// @Description  Deletes a user by the given ID
+// @Description  Deletes a user by the given ID and returns the deleted user.
+// @Description  Deletion is permanent, so repeating it for the same ID returns 404.
+// @Description  With dry_run=true the user that would be deleted is returned but kept.
 // @Tags         users
 // @Produce      json
-// @Param        id   path      int  true  "User ID"
-// @Success      204  {object}  nil
-// @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
+// @Param        id       path      int   true   "User ID"
+// @Param        dry_run  query     bool  false  "Preview without committing"
+// @Success      200      {object}  store.User
+// @Failure      400      {object}  map[string]string
+// @Failure      404      {object}  NotFoundResponse
 // @Router       /users/{id} [delete]
 func DeleteUser(c echo.Context) error {
 	id := c.Param("id")
@@ -151,25 +615,31 @@ func DeleteUser(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid user ID"})
 	}
 
-	for i, u := range users {
-		if u.ID == idInt {
-			// remove from slice
-			users = append(users[:i], users[i+1:]...)
-			return c.NoContent(http.StatusNoContent)
-		}
+	dryRun, err := isDryRun(c)
+	if err != nil {
+		return invalidDryRun(c)
+	}
+
+	done := timeStore(c)
+	deleted, err := usersFor(c).Delete(idInt, dryRun)
+	done()
+	if err != nil {
+		return storeError(c, err)
 	}
-	return c.JSON(http.StatusNotFound, echo.Map{"error": "User not found"})
+	return c.JSON(http.StatusOK, deleted)
 }
 
 // GetUserByID godoc
 // @Summary      Get user by ID
-// @Description  Retrieves a user by ID
+// @Description  Retrieves a user by ID.
+// @Description  With "Accept: text/vcard" the user is returned as a vCard 3.0 download instead.
 // @Tags         users
 // @Produce      json
+// @Produce      text/vcard
 // @Param        id   path      int  true  "User ID"
-// @Success      200  {object}  User
+// @Success      200  {object}  store.User
 // @Failure      400  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
+// @Failure      404  {object}  NotFoundResponse
 // @Router       /users/{id} [get]
 func GetUserByID(c echo.Context) error {
 	id := c.Param("id")
@@ -179,23 +649,234 @@ func GetUserByID(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid user ID"})
 	}
 
-	for _, user := range users {
-		if user.ID == idInt {
-			c.Logger().Debug("Fetching user by ID")
-			return c.JSON(http.StatusOK, user)
+	done := timeStore(c)
+	user, err := lookupUser(c, idInt)
+	done()
+	if err != nil {
+		return storeError(c, err)
+	}
+	c.Logger().Debug("Fetching user by ID")
+	// JSON or a vCard by Accept; shared caches must keep them apart
+	addVary(c.Response().Header(), echo.HeaderAccept)
+	if namesMediaType(c.Request().Header.Get(echo.HeaderAccept), mimeVCard) {
+		return writeVCard(c, user)
+	}
+	return c.JSON(http.StatusOK, user)
+}
+
+// GetUserByName godoc
+// @Summary      Get user by name
+// @Description  Returns the user with the given name, compared case-insensitively and Unicode-normalized like the uniqueness check. Names are unique in that form, so at most one user matches.
+// @Tags         users
+// @Produce      json
+// @Param        name  path      string  true  "User name"
+// @Success      200   {object}  store.User
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Router       /users/by-name/{name} [get]
+func GetUserByName(c echo.Context) error {
+	// echo routes on the raw path when it has one, e.g. for an escaped "/",
+	// and leaves its params escaped
+	name := c.Param("name")
+	if c.Request().URL.RawPath != "" {
+		var err error
+		if name, err = url.PathUnescape(name); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid user name"})
 		}
 	}
-	return c.JSON(http.StatusNotFound, echo.Map{"error": "User not found"})
+
+	done := timeStore(c)
+	u, err := usersFor(c).GetByName(name)
+	done()
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, u)
+}
+
+// selectUsers returns the users matching p, in p's order: the listing
+// shared by GET /users and GET /users.html.
+func selectUsers(c echo.Context, p ListParams) []store.User {
+	done := timeStore(c)
+	list := usersFor(c).List()
+	done()
+
+	matched := []store.User{}
+	for _, u := range list {
+		if since := p.ModifiedSince.Value; since == nil || !u.UpdatedAt.Before(*since) {
+			matched = append(matched, u)
+		}
+	}
+	sortUsers(matched, p.sortKeys)
+	return matched
 }
 
 // GetUsers godoc
 // @Summary      Get all users
-// @Description  Retrieves a list of all users
+// @Description  Retrieves a page of users, optionally only those changed since a point in time.
+// @Description  A page past the last one returns 200 with empty data and out_of_range set.
+// @Description  Instead of page/limit a "Range: items=0-49" header may be sent; the window, of at most MAX_PAGE_LIMIT items, is then returned as a bare array with 206 and a Content-Range header. A Range in another unit is ignored.
 // @Tags         users
 // @Produce      json
-// @Success      200  {array}   User
+// @Param        Range           header    string  false  "Item window, e.g. items=0-49"
+// @Param        If-Range        header    string  false  "Strong ETag of a cached window, as sent with the 206; the Range is only honored if it still matches"
+// @Param        modified_since  query     string  false  "Only users created or updated at or after this RFC3339 time"
+// @Param        sort            query     string  false  "Comma-separated sort keys (id, name, age), prefix with - for descending, e.g. age,-name (default DEFAULT_SORT)"
+// @Param        page            query     int     false  "Page number (default 1)"
+// @Param        limit           query     int     false  "Page size (default DEFAULT_PAGE_LIMIT, max MAX_PAGE_LIMIT)"
+// @Success      200             {object}  Page[store.User]
+// @Success      206             {array}   store.User
+// @Failure      400             {object}  map[string]string
+// @Failure      416             {object}  map[string]string
 // @Router       /users [get]
 func GetUsers(c echo.Context) error {
 	c.Logger().Debug("Fetching all users")
-	return c.JSON(http.StatusOK, users)
+
+	window, err := parseItemsRange(c.Request().Header.Get("Range"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	params, errs := bindListParams(c)
+	if len(errs) > 0 {
+		return listParamsError(c, errs)
+	}
+	matched := selectUsers(c, params)
+
+	h := c.Response().Header()
+	h.Set("Accept-Ranges", "items")
+	if window != nil {
+		// a window is part of the whole selection as a bare array, so that
+		// carries the tag If-Range names; strong, since a window is only
+		// valid for a byte-identical selection
+		body, err := json.Marshal(matched)
+		if err != nil {
+			return err
+		}
+		tag := strongETag(echo.MIMEApplicationJSON, body)
+		if ifRange := c.Request().Header.Get("If-Range"); ifRange == "" || ifRangeMatches(ifRange, tag) {
+			h.Set("ETag", tag)
+			return writeItemsRange(c, matched, window)
+		}
+		// the client's partial copy is stale: send everything
+	}
+	// the page is tagged by ETag from the envelope actually sent
+	result := paginate(matched, params.page, params.limit)
+	result.Filtered = params.filtered()
+	return c.JSON(http.StatusOK, result)
+}
+
+// UserExists godoc
+// @Summary      Check whether a name is taken
+// @Description  Reports whether a user with the given name (case-insensitive) exists
+// @Tags         users
+// @Produce      json
+// @Param        name  query     string  true  "User name"
+// @Success      200   {object}  map[string]bool
+// @Failure      400   {object}  map[string]string
+// @Router       /users/exists [get]
+func UserExists(c echo.Context) error {
+	name := c.QueryParam("name")
+	if name == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Missing name query parameter"})
+	}
+
+	done := timeStore(c)
+	exists := usersFor(c).NameTaken(name, 0)
+	done()
+
+	return c.JSON(http.StatusOK, echo.Map{"exists": exists})
+}
+
+// ExistsBatchRequest lists the names to look up, at most 100 per request.
+type ExistsBatchRequest struct {
+	Names []string `json:"names" validate:"required,min=1,max=100" example:"Agus,Zed"`
+}
+
+// UsersExistBatch godoc
+// @Summary      Check which names are taken
+// @Description  Reports for each given name whether a user with that name (case-insensitive) exists. At most 100 names per request.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      ExistsBatchRequest  true  "Names to check"
+// @Success      200      {object}  map[string]bool
+// @Failure      400      {object}  map[string]string
+// @Failure      422      {object}  map[string]string
+// @Router       /users/exists-batch [post]
+func UsersExistBatch(c echo.Context) error {
+	var req ExistsBatchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": bindErrorMessage(err)})
+	}
+
+	if err := validateRequest(c, &req); err != nil {
+		return validationError(c, err)
+	}
+
+	done := timeStore(c)
+	taken := usersFor(c).NamesTaken(req.Names)
+	done()
+
+	return c.JSON(http.StatusOK, taken)
+}
+
+// GetBatchRequest lists the user IDs to fetch, at most 100 per request.
+type GetBatchRequest struct {
+	IDs []int `json:"ids" validate:"required,min=1,max=100" example:"1,2"`
+}
+
+// GetUsersBatch godoc
+// @Summary      Fetch users by ID
+// @Description  Returns an object keyed by each requested ID, mapping to the user or to null when there is no user with that ID. All users are read from the same snapshot. At most 100 IDs per request.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      GetBatchRequest  true  "IDs to fetch"
+// @Success      200      {object}  map[string]store.User
+// @Failure      400      {object}  map[string]string
+// @Failure      422      {object}  map[string]string
+// @Router       /users/get-batch [post]
+func GetUsersBatch(c echo.Context) error {
+	var req GetBatchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": bindErrorMessage(err)})
+	}
+
+	if err := validateRequest(c, &req); err != nil {
+		return validationError(c, err)
+	}
+
+	done := timeStore(c)
+	list := usersFor(c).List()
+	done()
+
+	byID := make(map[int]store.User, len(list))
+	for _, u := range list {
+		byID[u.ID] = u
+	}
+	result := make(map[int]*store.User, len(req.IDs))
+	for _, id := range req.IDs {
+		if u, ok := byID[id]; ok {
+			result[id] = &u
+		} else {
+			result[id] = nil
+		}
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetNextID godoc
+// @Summary      Next user ID
+// @Description  Returns the ID the next POST /users would assign. This is advisory: nothing is reserved, so a concurrent create can take it first. IDs are never reused, deleted ones included.
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  map[string]int
+// @Router       /users/next-id [get]
+func GetNextID(c echo.Context) error {
+	done := timeStore(c)
+	id := usersFor(c).NextID()
+	done()
+
+	return c.JSON(http.StatusOK, echo.Map{"next_id": id})
 }
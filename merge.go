@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MergeRequest names the user to fold into the target. The target's values
+// win unless a field is listed in TakeFromSource.
+type MergeRequest struct {
+	SourceID       int      `json:"source_id" validate:"required" example:"2"`
+	TakeFromSource []string `json:"take_from_source" validate:"dive,oneof=name age" example:"age"`
+}
+
+// MergeUsers godoc
+// @Summary      Merge two users
+// @Description  Merges the source user into the target: the target keeps its values except for fields listed in take_from_source, and the source is deleted
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int           true  "Target user ID"
+// @Param        request  body      MergeRequest  true  "Source user and field overrides"
+// @Success      200      {object}  store.User
+// @Failure      400      {object}  map[string]string
+// @Failure      404      {object}  NotFoundResponse
+// @Failure      422      {object}  map[string]string
+// @Router       /users/{id}/merge [post]
+func MergeUsers(c echo.Context) error {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid user ID"})
+	}
+
+	var req MergeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": bindErrorMessage(err)})
+	}
+
+	if err := validateRequest(c, &req); err != nil {
+		return validationError(c, err)
+	}
+
+	if req.SourceID == targetID {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Cannot merge a user into itself"})
+	}
+
+	done := timeStore(c)
+	merged, err := usersFor(c).Merge(targetID, req.SourceID, req.TakeFromSource)
+	done()
+	if err != nil {
+		return storeError(c, err)
+	}
+	return c.JSON(http.StatusOK, merged)
+}
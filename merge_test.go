@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"go-echo/store"
+)
+
+func TestMergeUsers(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users/1/merge", `{"source_id":2,"take_from_source":["age"]}`)
+	expectStatus(t, rec, http.StatusOK)
+	if u := decode[store.User](t, rec); u.ID != 1 || u.Name != "Agus" || u.Age != 25 {
+		t.Errorf("merged %+v, want Agus aged 25", u)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users/2", ""), http.StatusNotFound)
+
+	rec = tc.Do(http.MethodGet, "/changelog", "")
+	expectStatus(t, rec, http.StatusOK)
+	changes := decode[Page[store.Change]](t, rec).Data
+	var updated, deleted bool
+	for _, ch := range changes {
+		updated = updated || ch.Action == store.ActionUpdate && ch.UserID == 1
+		deleted = deleted || ch.Action == store.ActionDelete && ch.UserID == 2
+	}
+	if !updated || !deleted {
+		t.Errorf("merge not in the changelog: %+v", changes)
+	}
+}
+
+func TestMergeRejects(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	expectStatus(t, tc.Do(http.MethodPost, "/users/1/merge", `{"source_id":1}`), http.StatusBadRequest)
+	expectStatus(t, tc.Do(http.MethodPost, "/users/1/merge", `{"source_id":42}`), http.StatusNotFound)
+	expectStatus(t, tc.Do(http.MethodPost, "/users/42/merge", `{"source_id":1}`), http.StatusNotFound)
+	expectStatus(t, tc.Do(http.MethodPost, "/users/1/merge", `{"source_id":2,"take_from_source":["id"]}`), http.StatusUnprocessableEntity)
+	// nothing was merged on the way
+	tc.GetUser(2)
+}
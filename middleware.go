@@ -0,0 +1,426 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/labstack/gommon/log"
+)
+
+// supportedMediaTypes lists the representations the API routes can produce.
+// Errors come as problem details to clients asking for them, see
+// ProblemDetails.
+var supportedMediaTypes = []string{
+	echo.MIMEApplicationJSON,
+	mimeProblemJSON,
+}
+
+// routeMediaTypes lists, by route path, representations a route offers on
+// top of supportedMediaTypes. The handler picks them when Accept names them
+// explicitly.
+var routeMediaTypes = map[string][]string{
+	"/users/:id": {mimeVCard},
+}
+
+// NegotiateAccept rejects requests whose Accept header names none of the
+// supported media types with 406. An absent header or a wildcard is served
+// as JSON.
+func NegotiateAccept(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		accept := c.Request().Header.Get(echo.HeaderAccept)
+		if accept == "" || acceptsAny(accept, supportedMediaTypes) || acceptsAny(accept, routeMediaTypes[c.Path()]) {
+			return next(c)
+		}
+		return c.JSON(http.StatusNotAcceptable, echo.Map{"error": "Unsupported Accept header: " + accept})
+	}
+}
+
+// acceptsAny reports whether the Accept header value allows at least one of
+// the given media types. Entries with q=0 are treated as refusals.
+func acceptsAny(accept string, types []string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if q, ok := params["q"]; ok && strings.Trim(q, "0.") == "" {
+			continue
+		}
+		for _, t := range types {
+			if mediaTypeMatches(mediaType, t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// namesMediaType reports whether the Accept header value lists t itself,
+// not just through a wildcard, with a non-zero quality.
+func namesMediaType(accept, t string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mediaType != t {
+			continue
+		}
+		if q, ok := params["q"]; ok && strings.Trim(q, "0.") == "" {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// mediaTypeMatches reports whether the pattern (which may be "*/*" or
+// "type/*") covers the concrete media type t.
+func mediaTypeMatches(pattern, t string) bool {
+	if pattern == "*/*" || pattern == t {
+		return true
+	}
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(t, strings.TrimSuffix(pattern, "*"))
+	}
+	return false
+}
+
+// ForceHTTPS refuses plaintext requests and marks HTTPS responses with
+// Strict-Transport-Security. The scheme comes from echo's Context.Scheme,
+// which honours X-Forwarded-Proto, so it must only be enabled behind a proxy
+// that sets that header. GET and HEAD are redirected to https with 301; other
+// methods get 400, since their body already crossed the wire in plaintext.
+func ForceHTTPS(hstsMaxAge int) echo.MiddlewareFunc {
+	hsts := "max-age=" + strconv.Itoa(hstsMaxAge)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Scheme() == "https" {
+				c.Response().Header().Set(echo.HeaderStrictTransportSecurity, hsts)
+				return next(c)
+			}
+
+			req := c.Request()
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				return c.JSON(http.StatusBadRequest, echo.Map{"error": "HTTPS is required"})
+			}
+			return c.Redirect(http.StatusMovedPermanently, "https://"+req.Host+req.RequestURI)
+		}
+	}
+}
+
+// MaxURLLength rejects requests whose request target, path and query
+// together, is longer than maxLen bytes with 414. Long ID lists and filters
+// belong in the body of POST /users/search or /users/get-batch instead.
+func MaxURLLength(maxLen int) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if len(c.Request().RequestURI) > maxLen {
+				return c.JSON(http.StatusRequestURITooLong, echo.Map{
+					"error": fmt.Sprintf("URL is longer than %d bytes; send long queries in the body of POST /users/search or /users/get-batch", maxLen),
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireHeader rejects requests that lack the named header, or send it
+// empty, with 400.
+func RequireHeader(name string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if strings.TrimSpace(c.Request().Header.Get(name)) == "" {
+				return c.JSON(http.StatusBadRequest, echo.Map{"error": "Missing " + name + " header"})
+			}
+			return next(c)
+		}
+	}
+}
+
+// bufferedWriter holds back a response so it can be inspected before
+// anything reaches the client.
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) { w.status = status }
+
+func (w *bufferedWriter) Write(b []byte) (int, error) { return w.body.Write(b) }
+
+// ETag tags successful GET and HEAD responses with a weak ETag computed
+// from the body and its media type, so representations negotiated by
+// Accept never share a tag, and answers a matching If-None-Match with 304. Handlers may
+// set their own ETag first, which is then kept. The tag is computed before
+// any compression; it is weak because the compressed and identity bodies are
+// equivalent but not byte-identical, and Vary: Accept-Encoding is set so
+// shared caches keep the two apart.
+func ETag(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			return next(c)
+		}
+
+		res := c.Response()
+		orig := res.Writer
+		buf := &bufferedWriter{ResponseWriter: orig, status: http.StatusOK}
+		res.Writer = buf
+		err := next(c)
+		res.Writer = orig
+		if err != nil {
+			return err
+		}
+
+		h := res.Header()
+		addVary(h, echo.HeaderAcceptEncoding)
+		if buf.status == http.StatusOK {
+			tag := h.Get("ETag")
+			if tag == "" {
+				tag = weakETag(h.Get(echo.HeaderContentType), buf.body.Bytes())
+				h.Set("ETag", tag)
+			}
+			if etagMatches(req.Header.Get("If-None-Match"), tag) {
+				h.Del(echo.HeaderContentLength)
+				h.Del(echo.HeaderContentType)
+				orig.WriteHeader(http.StatusNotModified)
+				return nil
+			}
+		}
+
+		orig.WriteHeader(buf.status)
+		_, err = orig.Write(buf.body.Bytes())
+		return err
+	}
+}
+
+// weakETag is the weak entity tag of body sent as mediaType.
+func weakETag(mediaType string, body []byte) string {
+	return `W/"` + etagHash(mediaType, body) + `"`
+}
+
+// strongETag is the strong entity tag of body sent as mediaType, for
+// validators If-Range is checked against.
+func strongETag(mediaType string, body []byte) string {
+	return `"` + etagHash(mediaType, body) + `"`
+}
+
+// etagHash is the opaque part of the tags of body sent as mediaType.
+func etagHash(mediaType string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(mediaType))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// addVary adds name to the Vary header unless it is already listed.
+func addVary(h http.Header, name string) {
+	for _, v := range h.Values(echo.HeaderVary) {
+		for _, listed := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(listed), name) {
+				return
+			}
+		}
+	}
+	h.Add(echo.HeaderVary, name)
+}
+
+// etagMatches reports whether an If-None-Match value lists tag or is "*".
+// Comparison is weak, as RFC 9110 prescribes for If-None-Match.
+func etagMatches(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ifRangeMatches reports whether an If-Range value is the entity tag tag.
+// Comparison is strong, RFC 9110 §13.1.5: a weak tag on either side never
+// matches, nor does a date, since no Last-Modified is sent.
+func ifRangeMatches(header, tag string) bool {
+	header = strings.TrimSpace(header)
+	return !strings.HasPrefix(header, "W/") && !strings.HasPrefix(tag, "W/") && header == tag
+}
+
+// maxRequestIDLen bounds the incoming request IDs RequestID accepts.
+const maxRequestIDLen = 128
+
+// RequestID makes sure every request carries an ID in the named header. A
+// valid incoming ID is kept, so callers can correlate across services;
+// otherwise a random one is generated. Either way it is set on the request,
+// for handlers and logs, and echoed in the response.
+func RequestID(header string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			id := req.Header.Get(header)
+			if !validRequestID(id) {
+				id = newRequestID()
+				req.Header.Set(header, id)
+			}
+			c.Response().Header().Set(header, id)
+			return next(c)
+		}
+	}
+}
+
+// validRequestID reports whether id is a usable incoming request ID: up to
+// maxRequestIDLen printable ASCII characters, without spaces.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 128-bit ID in hex.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// SlowRequests logs, at WARN, every request that takes longer than
+// threshold, with its method, path and duration.
+func SlowRequests(threshold time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if elapsed := time.Since(start); elapsed > threshold {
+				c.Logger().Warnj(log.JSON{
+					"message":  "slow request",
+					"method":   c.Request().Method,
+					"path":     c.Request().URL.Path,
+					"status":   c.Response().Status,
+					"duration": elapsed.String(),
+				})
+			}
+			return err
+		}
+	}
+}
+
+// serverTimingKey is the context key under which ServerTiming keeps the
+// request's timings.
+const serverTimingKey = "server-timing"
+
+// serverTimings accumulates the durations reported in Server-Timing.
+type serverTimings struct {
+	store time.Duration
+}
+
+// ServerTiming reports, in a Server-Timing header, how long the request
+// spent in the handler chain ("app") and in store calls ("store"), both in
+// milliseconds. The header is added just before the response is written, so
+// "app" covers everything up to the first byte.
+func ServerTiming(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		timings := &serverTimings{}
+		c.Set(serverTimingKey, timings)
+
+		c.Response().Before(func() {
+			c.Response().Header().Set("Server-Timing", fmt.Sprintf("store;dur=%.3f, app;dur=%.3f",
+				millis(timings.store), millis(time.Since(start))))
+		})
+		return next(c)
+	}
+}
+
+// timeStore starts timing a store call for Server-Timing; call the returned
+// func when it returns. It does nothing when ServerTiming is not installed.
+func timeStore(c echo.Context) func() {
+	timings, ok := c.Get(serverTimingKey).(*serverTimings)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { timings.store += time.Since(start) }
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// redactedFields are JSON keys (matched case-insensitively) whose values are
+// masked before bodies are logged.
+var redactedFields = map[string]bool{
+	"email":    true,
+	"password": true,
+	"token":    true,
+	"secret":   true,
+}
+
+// LogBodies logs request and response bodies, redacted and truncated to
+// maxBytes. The dump middleware buffers the request body and restores it, so
+// handlers can still bind it.
+func LogBodies(maxBytes int) echo.MiddlewareFunc {
+	return middleware.BodyDump(func(c echo.Context, reqBody, resBody []byte) {
+		c.Logger().Printj(log.JSON{
+			"method":   c.Request().Method,
+			"uri":      c.Request().RequestURI,
+			"status":   c.Response().Status,
+			"request":  truncate(redactBody(reqBody), maxBytes),
+			"response": truncate(redactBody(resBody), maxBytes),
+		})
+	})
+}
+
+// redactBody masks redactedFields anywhere in a JSON body. Bodies that are
+// not JSON are returned unchanged.
+func redactBody(body []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return string(body)
+	}
+	return string(out)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if redactedFields[strings.ToLower(k)] {
+				t[k] = "[REDACTED]"
+			} else {
+				t[k] = redactValue(val)
+			}
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = redactValue(val)
+		}
+	}
+	return v
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "...(truncated)"
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestSlowRequestsLoggedAtDefaultLevel(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.SlowRequestThreshold = 20 * time.Millisecond
+	})
+	var logs bytes.Buffer
+	tc.e.Logger.SetOutput(&logs)
+	tc.e.GET("/slow", func(c echo.Context) error {
+		time.Sleep(40 * time.Millisecond)
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	expectStatus(t, tc.Do(http.MethodGet, "/slow", ""), http.StatusNoContent)
+	if !strings.Contains(logs.String(), `"level":"WARN"`) || !strings.Contains(logs.String(), `"slow request"`) ||
+		!strings.Contains(logs.String(), `"path":"/slow"`) {
+		t.Errorf("no slow request warning at LOG_LEVEL %s; log: %q", cfg().LogLevel, logs.String())
+	}
+}
+
+func TestFastRequestsNotLogged(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.SlowRequestThreshold = time.Hour
+	})
+	var logs bytes.Buffer
+	tc.e.Logger.SetOutput(&logs)
+
+	expectStatus(t, tc.Do(http.MethodGet, "/users", ""), http.StatusOK)
+	if strings.Contains(logs.String(), `"slow request"`) {
+		t.Errorf("fast request logged as slow; log: %q", logs.String())
+	}
+}
+
+func TestNegotiateAccept(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	expectStatus(t, tc.Do(http.MethodGet, "/users", "", "Accept", "text/xml"), http.StatusNotAcceptable)
+	expectStatus(t, tc.Do(http.MethodGet, "/users", "", "Accept", "application/json;q=0, text/xml"), http.StatusNotAcceptable)
+	expectStatus(t, tc.Do(http.MethodGet, "/users", "", "Accept", "*/*"), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodGet, "/users", "", "Accept", "text/html, application/json"), http.StatusOK)
+}
+
+func TestLogBodiesRedactsEmail(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.LogBodies = true
+	})
+	var logs bytes.Buffer
+	tc.e.Logger.SetOutput(&logs)
+
+	rec := tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":31,"email":"dewi@example.com"}`)
+	// the handler still reads the body after it was dumped
+	expectStatus(t, rec, http.StatusCreated)
+	if strings.Contains(logs.String(), "dewi@example.com") || !strings.Contains(logs.String(), `[REDACTED]`) {
+		t.Errorf("email not redacted; log: %q", logs.String())
+	}
+	if !strings.Contains(logs.String(), "Dewi") {
+		t.Errorf("request body not logged; log: %q", logs.String())
+	}
+}
+
+func TestLogBodiesTruncates(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.LogBodies = true
+		conf.LogBodyMaxBytes = 8
+	})
+	var logs bytes.Buffer
+	tc.e.Logger.SetOutput(&logs)
+
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"Dewi Ayu Lestari","age":31}`), http.StatusCreated)
+	if strings.Contains(logs.String(), "Lestari") {
+		t.Errorf("body not truncated; log: %q", logs.String())
+	}
+}
+
+func TestForceHTTPS(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.ForceHTTPS = true
+		conf.HSTSMaxAge = 600
+	})
+
+	rec := tc.Do(http.MethodGet, "/users?limit=1", "")
+	expectStatus(t, rec, http.StatusMovedPermanently)
+	if loc := rec.Header().Get("Location"); loc != "https://example.com/users?limit=1" {
+		t.Errorf("Location %q", loc)
+	}
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":31}`), http.StatusBadRequest)
+
+	rec = tc.Do(http.MethodGet, "/users", "", "X-Forwarded-Proto", "https")
+	expectStatus(t, rec, http.StatusOK)
+	if hsts := rec.Header().Get("Strict-Transport-Security"); hsts != "max-age=600" {
+		t.Errorf("Strict-Transport-Security %q", hsts)
+	}
+}
+
+func TestServerTiming(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.ServerTiming = true
+	})
+
+	rec := tc.Do(http.MethodGet, "/users/1", "")
+	expectStatus(t, rec, http.StatusOK)
+	header := rec.Header().Get("Server-Timing")
+	if !regexp.MustCompile(`^store;dur=\d+\.\d{3}, app;dur=\d+\.\d{3}$`).MatchString(header) {
+		t.Errorf("Server-Timing %q", header)
+	}
+
+	off := newTestClient(t, nil)
+	if header := off.Do(http.MethodGet, "/users/1", "").Header().Get("Server-Timing"); header != "" {
+		t.Errorf("Server-Timing %q sent while off", header)
+	}
+}
+
+func TestETagWithGzip(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Gzip = true
+	})
+
+	plain := tc.Do(http.MethodGet, "/users/1", "")
+	gzipped := tc.Do(http.MethodGet, "/users/1", "", "Accept-Encoding", "gzip")
+	expectStatus(t, plain, http.StatusOK)
+	expectStatus(t, gzipped, http.StatusOK)
+	if enc := gzipped.Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("Content-Encoding %q, want gzip", enc)
+	}
+	if plain.Header().Get("Content-Encoding") != "" {
+		t.Error("identity response compressed")
+	}
+
+	// the tag is taken before compression, so both forms share it
+	tag := plain.Header().Get("ETag")
+	if !strings.HasPrefix(tag, `W/"`) || gzipped.Header().Get("ETag") != tag {
+		t.Errorf("ETag %q plain, %q gzipped; want one weak tag", tag, gzipped.Header().Get("ETag"))
+	}
+	for _, rec := range []*httptest.ResponseRecorder{plain, gzipped} {
+		if !strings.Contains(strings.Join(rec.Header().Values("Vary"), ","), "Accept-Encoding") {
+			t.Errorf("Vary %v lacks Accept-Encoding", rec.Header().Values("Vary"))
+		}
+	}
+
+	expectStatus(t, tc.Do(http.MethodGet, "/users/1", "", "If-None-Match", tag), http.StatusNotModified)
+	expectStatus(t, tc.Do(http.MethodGet, "/users/1", "", "If-None-Match", tag, "Accept-Encoding", "gzip"), http.StatusNotModified)
+}
+
+func TestRequestIDHeader(t *testing.T) {
+	t.Setenv("REQUEST_ID_HEADER", "X-Correlation-ID")
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/users/1", "", "X-Correlation-ID", "abc-123")
+	if got := rec.Header().Get("X-Correlation-ID"); got != "abc-123" {
+		t.Errorf("incoming ID came back as %q", got)
+	}
+
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+	for _, incoming := range []string{"", "has space", strings.Repeat("x", maxRequestIDLen+1)} {
+		rec := tc.Do(http.MethodGet, "/users/1", "", "X-Correlation-ID", incoming)
+		if got := rec.Header().Get("X-Correlation-ID"); !generated.MatchString(got) {
+			t.Errorf("incoming %q: ID %q, want a generated one", incoming, got)
+		}
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != "" {
+		t.Errorf("default header sent too: %q", got)
+	}
+}
+
+func TestMaxURLLength(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.MaxURLLength = 64
+	})
+
+	expectStatus(t, tc.Do(http.MethodGet, "/users?sort=-age", ""), http.StatusOK)
+	rec := tc.Do(http.MethodGet, "/users?sort="+strings.Repeat("a", 64), "")
+	expectStatus(t, rec, http.StatusRequestURITooLong)
+	if !strings.Contains(rec.Body.String(), "POST /users/search") {
+		t.Errorf("414 body %s does not point at the POST variant", rec.Body)
+	}
+
+	tc = newTestClient(t, func(conf *Config) {
+		conf.MaxURLLength = 0
+	})
+	expectStatus(t, tc.Do(http.MethodGet, "/users?fields=name&x="+strings.Repeat("a", 4096), ""), http.StatusOK)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"go-echo/store"
+)
+
+func TestIDsAreNotReused(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	expectStatus(t, tc.Do(http.MethodDelete, "/users/3", ""), http.StatusOK)
+	next := decode[map[string]int](t, tc.Do(http.MethodGet, "/users/next-id", ""))
+	if next["next_id"] != 4 {
+		t.Errorf("next_id %d, want 4", next["next_id"])
+	}
+	if u := tc.CreateUser(store.User{Name: "Dewi", Age: 31}); u.ID != 4 {
+		t.Errorf("created ID %d, want 4", u.ID)
+	}
+
+	expectStatus(t, tc.Do(http.MethodDelete, "/users/4", ""), http.StatusOK)
+	if u := tc.CreateUser(store.User{Name: "Eka", Age: 20}); u.ID != 5 {
+		t.Errorf("created ID %d after deleting 4, want 5", u.ID)
+	}
+}
+
+func TestNextIDIsAdvisory(t *testing.T) {
+	tc := newTestClient(t, nil)
+	nextID := func() int {
+		t.Helper()
+		rec := tc.Do(http.MethodGet, "/users/next-id", "")
+		expectStatus(t, rec, http.StatusOK)
+		return decode[map[string]int](t, rec)["next_id"]
+	}
+
+	// asking reserves nothing
+	if a, b := nextID(), nextID(); a != 4 || b != 4 {
+		t.Errorf("next_id %d then %d, want 4 both times", a, b)
+	}
+	expectStatus(t, tc.Do(http.MethodPost, "/users?dry_run=true", `{"name":"Dewi","age":31}`), http.StatusOK)
+	want := nextID()
+	if u := tc.CreateUser(store.User{Name: "Dewi", Age: 31}); u.ID != want {
+		t.Errorf("created ID %d, next_id said %d", u.ID, want)
+	}
+	if got := nextID(); got != want+1 {
+		t.Errorf("next_id %d after create, want %d", got, want+1)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/ghodss/yaml"
+	"github.com/labstack/echo/v4"
+	"github.com/swaggo/swag"
+)
+
+// GetOpenAPIJSON serves the generated spec as JSON, the same document the
+// Swagger UI loads.
+func GetOpenAPIJSON(c echo.Context) error {
+	doc, err := swag.ReadDoc()
+	if err != nil {
+		return err
+	}
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSONCharsetUTF8, []byte(doc))
+}
+
+// GetOpenAPIYAML serves the generated spec converted to YAML.
+func GetOpenAPIYAML(c echo.Context) error {
+	doc, err := swag.ReadDoc()
+	if err != nil {
+		return err
+	}
+	out, err := yaml.JSONToYAML([]byte(doc))
+	if err != nil {
+		return err
+	}
+	return c.Blob(http.StatusOK, "application/yaml", out)
+}
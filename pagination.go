@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Page is the paginated envelope shared by every list endpoint.
+type Page[T any] struct {
+	Data       []T `json:"data"`
+	Total      int `json:"total"`
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	TotalPages int `json:"total_pages"`
+	// OutOfRange is set when page is past the last page. Data is then
+	// empty; page 1 of an empty list is not out of range.
+	OutOfRange bool `json:"out_of_range"`
+	// Filtered is set when the list was narrowed by a filter, telling "no
+	// matches" apart from "no users" when data is empty.
+	Filtered bool `json:"filtered"`
+}
+
+// resolvePagination applies defaults to unset page/limit values and checks
+// that page >= 1 and 1 <= limit <= the configured maximum. Violations are reported per
+// field; the returned values are only meaningful when errs is empty.
+func resolvePagination(page, limit *int) (int, int, map[string]string) {
+	errs := map[string]string{}
+
+	p := 1
+	if page != nil {
+		p = *page
+		if p < 1 {
+			errs["page"] = "must be at least 1"
+		}
+	}
+
+	conf := cfg()
+	l := conf.DefaultPageLimit
+	if limit != nil {
+		l = *limit
+		if l < 1 || l > conf.MaxPageLimit {
+			errs["limit"] = fmt.Sprintf("must be between 1 and %d", conf.MaxPageLimit)
+		}
+	}
+
+	return p, l, errs
+}
+
+// parsePagination reads the page and limit query parameters of c and
+// resolves them with resolvePagination.
+func parsePagination(c echo.Context) (int, int, map[string]string) {
+	errs := map[string]string{}
+
+	parse := func(name string) *int {
+		raw := c.QueryParam(name)
+		if raw == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			errs[name] = "must be an integer"
+			return nil
+		}
+		return &n
+	}
+	page, limit := parse("page"), parse("limit")
+
+	p, l, rangeErrs := resolvePagination(page, limit)
+	for field, msg := range rangeErrs {
+		errs[field] = msg
+	}
+	return p, l, errs
+}
+
+// queryCount reads an optional result-count query parameter such as limit,
+// which must lie between 1 and MAX_PAGE_LIMIT, returning def when absent.
+func queryCount(c echo.Context, name string, def int) (int, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if maxCount := cfg().MaxPageLimit; err != nil || n < 1 || n > maxCount {
+		return 0, fmt.Errorf("%s must be between 1 and %d", name, maxCount)
+	}
+	return n, nil
+}
+
+// paginationError writes the 400 for invalid pagination parameters.
+func paginationError(c echo.Context, errs map[string]string) error {
+	return c.JSON(http.StatusBadRequest, echo.Map{
+		"error":  "Invalid pagination parameters",
+		"fields": errs,
+	})
+}
+
+// paginate slices list to the requested page and wraps it in a Page.
+func paginate[T any](list []T, page, limit int) Page[T] {
+	total := len(list)
+	// compare before multiplying, a huge page would overflow
+	start := total
+	if page-1 <= total/limit {
+		start = min((page-1)*limit, total)
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+	totalPages := (total + limit - 1) / limit
+	return Page[T]{
+		Data:       list[start:end],
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+		OutOfRange: page > max(totalPages, 1),
+	}
+}
+
+// itemsRange is a parsed "Range: items=start-end" request; both ends are
+// inclusive and zero-based.
+type itemsRange struct {
+	start, end int
+}
+
+var errBadRange = errors.New(`Range must have the form "items=start-end"`)
+
+// parseItemsRange parses the Range header of a list request. It returns nil
+// when no items range was requested; a range in another unit, such as
+// bytes, is ignored as RFC 9110 section 14.2 asks, so the full page is sent.
+func parseItemsRange(header string) (*itemsRange, error) {
+	unit, spec, _ := strings.Cut(header, "=")
+	if !strings.EqualFold(strings.TrimSpace(unit), "items") {
+		return nil, nil
+	}
+	from, to, ok := strings.Cut(spec, "-")
+	if !ok {
+		return nil, errBadRange
+	}
+	start, err1 := strconv.Atoi(strings.TrimSpace(from))
+	end, err2 := strconv.Atoi(strings.TrimSpace(to))
+	if err1 != nil || err2 != nil || start < 0 || end < start {
+		return nil, errBadRange
+	}
+	return &itemsRange{start: start, end: end}, nil
+}
+
+// writeItemsRange answers a Range request over list: 206 with the window and
+// a Content-Range header, or 416 when the window starts past the end. Like
+// a page, a window holds at most MAX_PAGE_LIMIT items; a longer one is cut
+// short, which Content-Range reports.
+func writeItemsRange(c echo.Context, list []store.User, r *itemsRange) error {
+	total := len(list)
+	h := c.Response().Header()
+	if r.start >= total {
+		h.Set("Content-Range", fmt.Sprintf("items */%d", total))
+		return c.JSON(http.StatusRequestedRangeNotSatisfiable, echo.Map{"error": "Range start is beyond the last item"})
+	}
+
+	// start is below total here, so this cannot overflow
+	end := min(r.end, total-1, r.start+cfg().MaxPageLimit-1)
+	h.Set("Content-Range", fmt.Sprintf("items %d-%d/%d", r.start, end, total))
+	return c.JSON(http.StatusPartialContent, list[r.start:end+1])
+}
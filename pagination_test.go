@@ -0,0 +1,201 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"go-echo/store"
+)
+
+func TestPaginateHugePage(t *testing.T) {
+	page := paginate([]int{1, 2, 3}, 4611686018427387905, 3)
+	if len(page.Data) != 0 || !page.OutOfRange {
+		t.Errorf("got %d items, out_of_range %v; want none, true", len(page.Data), page.OutOfRange)
+	}
+}
+
+func TestHugePageOnListRoutes(t *testing.T) {
+	tc := newTestClient(t, nil)
+	const query = "?page=4611686018427387905&limit=3"
+
+	for _, target := range []string{"/users" + query, "/changelog" + query, "/users/name-counts" + query, "/users.html" + query} {
+		expectStatus(t, tc.Do(http.MethodGet, target, ""), http.StatusOK)
+	}
+	expectStatus(t, tc.Do(http.MethodPost, "/users/search", `{"page":4611686018427387905,"limit":3}`), http.StatusOK)
+}
+
+func TestPaginationBounds(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	for _, query := range []string{"page=0", "limit=0", "limit=101", "page=-1&limit=5"} {
+		expectStatus(t, tc.Do(http.MethodGet, "/users?"+query, ""), http.StatusBadRequest)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users?page=2&limit=100", ""), http.StatusOK)
+}
+
+func TestItemsRange(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/users", "", "Range", "items=1-5")
+	expectStatus(t, rec, http.StatusPartialContent)
+	if cr := rec.Header().Get("Content-Range"); cr != "items 1-2/3" {
+		t.Errorf("Content-Range %q, want items 1-2/3", cr)
+	}
+	if got := names(decode[[]store.User](t, rec)); !slices.Equal(got, []string{"Bagus", "Caca"}) {
+		t.Errorf("window %v, want [Bagus Caca]", got)
+	}
+
+	rec = tc.Do(http.MethodGet, "/users", "", "Range", "items=3-9")
+	expectStatus(t, rec, http.StatusRequestedRangeNotSatisfiable)
+	if cr := rec.Header().Get("Content-Range"); cr != "items */3" {
+		t.Errorf("Content-Range %q, want items */3", cr)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users", "", "Range", "items=5-1"), http.StatusBadRequest)
+
+	rec = tc.Do(http.MethodGet, "/users", "")
+	expectStatus(t, rec, http.StatusOK)
+	if page := decode[Page[store.User]](t, rec); len(page.Data) != 3 || rec.Header().Get("Content-Range") != "" {
+		t.Errorf("without Range: %d users, Content-Range %q", len(page.Data), rec.Header().Get("Content-Range"))
+	}
+
+	// another unit is ignored rather than rejected
+	rec = tc.Do(http.MethodGet, "/users", "", "Range", "bytes=0-10")
+	expectStatus(t, rec, http.StatusOK)
+	if page := decode[Page[store.User]](t, rec); len(page.Data) != 3 || rec.Header().Get("Content-Range") != "" {
+		t.Errorf("bytes Range: %d users, Content-Range %q", len(page.Data), rec.Header().Get("Content-Range"))
+	}
+}
+
+func TestItemsRangeCappedAtMaxPageLimit(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.MaxPageLimit = 2
+	})
+
+	rec := tc.Do(http.MethodGet, "/users", "", "Range", "items=0-1000000")
+	expectStatus(t, rec, http.StatusPartialContent)
+	if cr := rec.Header().Get("Content-Range"); cr != "items 0-1/3" {
+		t.Errorf("Content-Range %q, want items 0-1/3", cr)
+	}
+	if got := names(decode[[]store.User](t, rec)); !slices.Equal(got, []string{"Agus", "Bagus"}) {
+		t.Errorf("window %v, want [Agus Bagus]", got)
+	}
+}
+
+func TestPaginateTotalPages(t *testing.T) {
+	for _, tt := range []struct {
+		total, page, limit int
+		totalPages, items  int
+		outOfRange         bool
+	}{
+		{total: 0, page: 1, limit: 10, totalPages: 0, items: 0},
+		{total: 0, page: 2, limit: 10, totalPages: 0, items: 0, outOfRange: true},
+		{total: 20, page: 2, limit: 10, totalPages: 2, items: 10},
+		{total: 21, page: 3, limit: 10, totalPages: 3, items: 1},
+		{total: 21, page: 4, limit: 10, totalPages: 3, items: 0, outOfRange: true},
+		{total: 1, page: 1, limit: 1, totalPages: 1, items: 1},
+	} {
+		page := paginate(make([]int, tt.total), tt.page, tt.limit)
+		if page.TotalPages != tt.totalPages || len(page.Data) != tt.items || page.OutOfRange != tt.outOfRange ||
+			page.Total != tt.total || page.Page != tt.page || page.Limit != tt.limit {
+			t.Errorf("paginate(%d items, page %d, limit %d) = %+v", tt.total, tt.page, tt.limit, page)
+		}
+	}
+}
+
+func TestIfRange(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	window := tc.Do(http.MethodGet, "/users", "", "Range", "items=0-1")
+	expectStatus(t, window, http.StatusPartialContent)
+	tag := window.Header().Get("ETag")
+	if !strings.HasPrefix(tag, `"`) {
+		t.Fatalf("window ETag %q, want a strong tag", tag)
+	}
+
+	// fresh: the window is served
+	rec := tc.Do(http.MethodGet, "/users", "", "Range", "items=0-1", "If-Range", tag)
+	expectStatus(t, rec, http.StatusPartialContent)
+	if rec.Header().Get("ETag") != tag {
+		t.Errorf("window ETag %q, want %q", rec.Header().Get("ETag"), tag)
+	}
+
+	// weak tags never match, neither the window's nor the page's
+	full := tc.Do(http.MethodGet, "/users", "")
+	for _, weak := range []string{"W/" + tag, full.Header().Get("ETag")} {
+		rec = tc.Do(http.MethodGet, "/users", "", "Range", "items=0-1", "If-Range", weak)
+		expectStatus(t, rec, http.StatusOK)
+		if rec.Header().Get("Content-Range") != "" {
+			t.Errorf("If-Range %s: Content-Range %q", weak, rec.Header().Get("Content-Range"))
+		}
+	}
+
+	// stale: everything is sent
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `{"age":16}`), http.StatusOK)
+	rec = tc.Do(http.MethodGet, "/users", "", "Range", "items=0-1", "If-Range", tag)
+	expectStatus(t, rec, http.StatusOK)
+	if page := decode[Page[store.User]](t, rec); len(page.Data) != 3 || rec.Header().Get("Content-Range") != "" {
+		t.Errorf("stale If-Range: %d users, Content-Range %q", len(page.Data), rec.Header().Get("Content-Range"))
+	}
+}
+
+func TestPageETagTracksTheEnvelope(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	three := tc.Do(http.MethodGet, "/users?limit=3", "")
+	tag := three.Header().Get("ETag")
+	if !strings.HasPrefix(tag, `W/"`) {
+		t.Fatalf("page ETag %q, want a weak tag", tag)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users?limit=3", "", "If-None-Match", tag), http.StatusNotModified)
+	// same users, different page: not the cached copy
+	rec := tc.Do(http.MethodGet, "/users?limit=2", "", "If-None-Match", tag)
+	expectStatus(t, rec, http.StatusOK)
+	if rec.Header().Get("ETag") == tag {
+		t.Errorf("pages of limit 2 and 3 share the ETag %s", tag)
+	}
+}
+
+func TestOutOfRangePage(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/users?page=2&limit=2", "")
+	expectStatus(t, rec, http.StatusOK)
+	if page := decode[Page[store.User]](t, rec); len(page.Data) != 1 || page.OutOfRange || page.TotalPages != 2 {
+		t.Errorf("last page %s", rec.Body)
+	}
+
+	rec = tc.Do(http.MethodGet, "/users?page=3&limit=2", "")
+	expectStatus(t, rec, http.StatusOK)
+	if strings.Contains(rec.Body.String(), `"data":null`) {
+		t.Errorf("data is null: %s", rec.Body)
+	}
+	if page := decode[Page[store.User]](t, rec); len(page.Data) != 0 || !page.OutOfRange || page.Total != 3 {
+		t.Errorf("page past the end %s", rec.Body)
+	}
+}
+
+func TestFilteredFlag(t *testing.T) {
+	check := func(tc *testClient, method, target, body string, filtered bool) {
+		t.Helper()
+		rec := tc.Do(method, target, body)
+		expectStatus(t, rec, http.StatusOK)
+		page := decode[Page[store.User]](t, rec)
+		if len(page.Data) != 0 || page.Filtered != filtered || !strings.Contains(rec.Body.String(), `"data":[]`) {
+			t.Errorf("%s %s %s: %s, want filtered %v", method, target, body, rec.Body, filtered)
+		}
+	}
+
+	// no users yet; sorting and paging are not filters
+	empty := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{}
+	})
+	check(empty, http.MethodGet, "/users?sort=-age&page=1&limit=5", "", false)
+	check(empty, http.MethodPost, "/users/search", `{"sort":"name"}`, false)
+
+	// no results for the filter
+	tc := newTestClient(t, nil)
+	check(tc, http.MethodGet, "/users?modified_since=2999-01-01T00:00:00Z", "", true)
+	check(tc, http.MethodPost, "/users/search", `{"name":"zed"}`, true)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"go-echo/store"
+)
+
+func TestReturnMinimal(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":31}`, "Prefer", "return=minimal")
+	expectStatus(t, rec, http.StatusNoContent)
+	if loc := rec.Header().Get("Location"); loc != "/users/4" {
+		t.Errorf("Location %q, want /users/4", loc)
+	}
+}
+
+func TestReturnMinimalDryRun(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users?dry_run=true", `{"name":"Dewi","age":31}`, "Prefer", "return=minimal")
+	expectStatus(t, rec, http.StatusOK)
+	if loc := rec.Header().Get("Location"); loc != "" {
+		t.Errorf("dry run sent Location %q", loc)
+	}
+	if got := decode[UserResponse](t, rec); got.Name != "Dewi" {
+		t.Errorf("body %s", rec.Body)
+	}
+}
+
+func TestPreferOnUpdate(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPut, "/users/1", `{"name":"Agustina","age":16}`, "Prefer", "return=minimal")
+	expectStatus(t, rec, http.StatusNoContent)
+	if rec.Header().Get("Location") != "/users/1" || rec.Header().Get("Preference-Applied") != "return=minimal" || rec.Body.Len() != 0 {
+		t.Errorf("minimal PUT: headers %v, body %q", rec.Header(), rec.Body)
+	}
+
+	rec = tc.Do(http.MethodPatch, "/users/1", `{"age":17}`, "Prefer", "return=representation")
+	expectStatus(t, rec, http.StatusOK)
+	if rec.Header().Get("Preference-Applied") != "return=representation" {
+		t.Errorf("Preference-Applied %q", rec.Header().Get("Preference-Applied"))
+	}
+	if u := decode[store.User](t, rec); u.Age != 17 {
+		t.Errorf("body %s", rec.Body)
+	}
+
+	// without a preference, or with one we do not know, the body is sent
+	// and nothing is confirmed
+	for _, prefer := range []string{"", "respond-async", "return=everything"} {
+		rec = tc.Do(http.MethodPatch, "/users/1", `{"age":18}`, "Prefer", prefer)
+		expectStatus(t, rec, http.StatusOK)
+		if got := rec.Header().Get("Preference-Applied"); got != "" {
+			t.Errorf("Prefer %q: Preference-Applied %q", prefer, got)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// mimeProblemJSON is the RFC 7807 problem details media type.
+const mimeProblemJSON = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body. Members of the regular error
+// envelope other than the message are carried over as extensions, and
+// validation failures add an errors extension.
+type Problem struct {
+	Type     string       `json:"type" example:"about:blank"`
+	Title    string       `json:"title" example:"Unprocessable Entity"`
+	Status   int          `json:"status" example:"422"`
+	Detail   string       `json:"detail,omitempty" example:"name is required"`
+	Instance string       `json:"instance,omitempty" example:"/users"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// streamingRoutes are the route paths whose responses are flushed as they
+// are written, so ProblemDetails must never hold them back, whatever the
+// client sent in Accept.
+var streamingRoutes = map[string]bool{
+	"/changelog/tail": true,
+}
+
+// problemErrorsKey is the context key under which validationError leaves the
+// field errors for ProblemDetails.
+const problemErrorsKey = "problem-errors"
+
+// ProblemDetails rewrites JSON error responses as application/problem+json
+// when the client names that type in Accept, or for everyone when
+// PROBLEM_JSON is set. Handlers keep writing the regular envelope; its
+// message becomes the detail. Streaming routes are passed through as they
+// are, errors included.
+func ProblemDetails(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		accept := c.Request().Header.Get(echo.HeaderAccept)
+		if streamingRoutes[c.Path()] || !cfg().ProblemJSON && !namesMediaType(accept, mimeProblemJSON) {
+			return next(c)
+		}
+
+		res := c.Response()
+		orig := res.Writer
+		buf := &bufferedWriter{ResponseWriter: orig, status: http.StatusOK}
+		res.Writer = buf
+		// errors returned by the handler are rendered here, while the
+		// response is still held back
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+		res.Writer = orig
+
+		body := buf.body.Bytes()
+		h := res.Header()
+		if buf.status >= 400 && strings.HasPrefix(h.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+			if problem, ok := toProblem(c, buf.status, body); ok {
+				body = problem
+				h.Set(echo.HeaderContentType, mimeProblemJSON)
+				h.Del(echo.HeaderContentLength)
+			}
+		}
+
+		orig.WriteHeader(buf.status)
+		_, err := orig.Write(body)
+		return err
+	}
+}
+
+// toProblem converts an error envelope to a problem details body. The
+// message is read from "error", either a string or an object with a
+// message, or from echo's "message"; the remaining members become
+// extensions. It returns false for bodies that are not a JSON object.
+func toProblem(c echo.Context, status int, envelope []byte) ([]byte, bool) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(envelope, &members); err != nil {
+		return nil, false
+	}
+
+	out := map[string]any{}
+	detail := ""
+	for name, raw := range members {
+		switch name {
+		case "error", "message":
+			var msg string
+			if json.Unmarshal(raw, &msg) == nil {
+				detail = msg
+				continue
+			}
+			// structured errors such as NotFoundResponse: hoist the members
+			var nested map[string]json.RawMessage
+			if json.Unmarshal(raw, &nested) != nil {
+				out[name] = raw
+				continue
+			}
+			for k, v := range nested {
+				if k == "message" {
+					_ = json.Unmarshal(v, &detail)
+					continue
+				}
+				out[k] = v
+			}
+		default:
+			out[name] = raw
+		}
+	}
+
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request().URL.Path,
+	}
+	if fields, ok := c.Get(problemErrorsKey).([]FieldError); ok {
+		problem.Errors = fields
+	}
+
+	// the standard members win over extensions of the same name
+	std, err := json.Marshal(problem)
+	if err != nil {
+		return nil, false
+	}
+	var stdMembers map[string]json.RawMessage
+	_ = json.Unmarshal(std, &stdMembers)
+	for k, v := range stdMembers {
+		out[k] = v
+	}
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, false
+	}
+	return append(b, '\n'), true
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestProblemJSONAccepted(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/users/99", "", "Accept", mimeProblemJSON)
+	expectStatus(t, rec, http.StatusNotFound)
+	if ct := rec.Header().Get("Content-Type"); ct != mimeProblemJSON {
+		t.Errorf("Content-Type %q, want %q", ct, mimeProblemJSON)
+	}
+	problem := decode[Problem](t, rec)
+	if problem.Status != http.StatusNotFound || problem.Instance != "/users/99" {
+		t.Errorf("problem = %+v", problem)
+	}
+}
+
+func TestProblemJSONValidation(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users", `{"name":"","age":-1}`, "Accept", mimeProblemJSON)
+	expectStatus(t, rec, http.StatusUnprocessableEntity)
+	if ct := rec.Header().Get("Content-Type"); ct != mimeProblemJSON {
+		t.Errorf("Content-Type %q, want %q", ct, mimeProblemJSON)
+	}
+	problem := decode[Problem](t, rec)
+	if problem.Type != "about:blank" || problem.Title != "Unprocessable Entity" || problem.Status != http.StatusUnprocessableEntity ||
+		problem.Detail == "" || problem.Instance != "/users" {
+		t.Errorf("problem = %+v", problem)
+	}
+	codes := map[string]string{}
+	for _, fe := range problem.Errors {
+		codes[fe.Field] = fe.Code
+	}
+	if codes["name"] != "REQUIRED" || codes["age"] != "MIN" {
+		t.Errorf("errors extension %+v", problem.Errors)
+	}
+}
+
+func TestProblemJSONForEveryone(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.ProblemJSON = true
+	})
+
+	rec := tc.Do(http.MethodGet, "/users/99", "")
+	expectStatus(t, rec, http.StatusNotFound)
+	if ct := rec.Header().Get("Content-Type"); ct != mimeProblemJSON {
+		t.Errorf("Content-Type %q, want %q", ct, mimeProblemJSON)
+	}
+	// successes are left alone
+	if ct := tc.Do(http.MethodGet, "/users/1", "").Header().Get("Content-Type"); ct == mimeProblemJSON {
+		t.Error("a 200 was sent as a problem")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NameCount is how many users share one name, compared case-insensitively.
+type NameCount struct {
+	Name  string `json:"name" example:"Agus"`
+	Count int    `json:"count" example:"2"`
+}
+
+// GetNameCounts godoc
+// @Summary      Count users per name
+// @Description  Lists each distinct name (case-insensitive) with the number of users sharing it, most common first
+// @Tags         reports
+// @Produce      json
+// @Param        page   query     int  false  "Page number (default 1)"
+// @Param        limit  query     int  false  "Page size (default DEFAULT_PAGE_LIMIT, max MAX_PAGE_LIMIT)"
+// @Success      200    {object}  Page[NameCount]
+// @Failure      400    {object}  map[string]string
+// @Router       /users/name-counts [get]
+func GetNameCounts(c echo.Context) error {
+	page, limit, errs := parsePagination(c)
+	if len(errs) > 0 {
+		return paginationError(c, errs)
+	}
+
+	done := timeStore(c)
+	list := usersFor(c).List()
+	done()
+
+	counts := map[string]*NameCount{}
+	for _, u := range list {
+		key := store.NameKey(u.Name)
+		if nc, ok := counts[key]; ok {
+			nc.Count++
+			continue
+		}
+		counts[key] = &NameCount{Name: u.Name, Count: 1}
+	}
+
+	result := make([]NameCount, 0, len(counts))
+	for _, nc := range counts {
+		result = append(result, *nc)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return store.NameKey(result[i].Name) < store.NameKey(result[j].Name)
+	})
+
+	return c.JSON(http.StatusOK, paginate(result, page, limit))
+}
+
+// Extremes holds the youngest and oldest users. Both are null when there are
+// no users.
+type Extremes struct {
+	Youngest *store.User `json:"youngest"`
+	Oldest   *store.User `json:"oldest"`
+}
+
+// GetExtremes godoc
+// @Summary      Youngest and oldest users
+// @Description  Returns the youngest and the oldest user, ties broken by lowest ID. With no users both fields are null.
+// @Tags         reports
+// @Produce      json
+// @Success      200  {object}  Extremes
+// @Router       /users/extremes [get]
+func GetExtremes(c echo.Context) error {
+	done := timeStore(c)
+	list := usersFor(c).List()
+	done()
+
+	var result Extremes
+	for i := range list {
+		u := &list[i]
+		if result.Youngest == nil || u.Age < result.Youngest.Age ||
+			(u.Age == result.Youngest.Age && u.ID < result.Youngest.ID) {
+			result.Youngest = u
+		}
+		if result.Oldest == nil || u.Age > result.Oldest.Age ||
+			(u.Age == result.Oldest.Age && u.ID < result.Oldest.ID) {
+			result.Oldest = u
+		}
+	}
+	return c.JSON(http.StatusOK, result)
+}
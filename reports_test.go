@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"go-echo/store"
+)
+
+func TestNameCounts(t *testing.T) {
+	// legacy data from before names had to be unique
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{
+			{ID: 1, Name: "Agus", Age: 15},
+			{ID: 2, Name: "Bagus", Age: 25},
+			{ID: 3, Name: "AGUS", Age: 29},
+			{ID: 4, Name: "agus", Age: 33},
+			{ID: 5, Name: "bagus", Age: 41},
+			{ID: 6, Name: "Caca", Age: 29},
+		}
+	})
+
+	rec := tc.Do(http.MethodGet, "/users/name-counts", "")
+	expectStatus(t, rec, http.StatusOK)
+	want := []NameCount{{"Agus", 3}, {"Bagus", 2}, {"Caca", 1}}
+	if got := decode[Page[NameCount]](t, rec).Data; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// deleted users are not counted
+	expectStatus(t, tc.Do(http.MethodDelete, "/users/5", ""), http.StatusOK)
+	rec = tc.Do(http.MethodGet, "/users/name-counts", "")
+	want = []NameCount{{"Agus", 3}, {"Bagus", 1}, {"Caca", 1}}
+	if got := decode[Page[NameCount]](t, rec).Data; !slices.Equal(got, want) {
+		t.Errorf("after delete got %v, want %v", got, want)
+	}
+}
+
+func TestExtremes(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{
+			{ID: 1, Name: "Agus", Age: 40},
+			{ID: 2, Name: "Bagus", Age: 18},
+			{ID: 3, Name: "Caca", Age: 40},
+			{ID: 4, Name: "Dewi", Age: 18},
+		}
+	})
+
+	rec := tc.Do(http.MethodGet, "/users/extremes", "")
+	expectStatus(t, rec, http.StatusOK)
+	// ties go to the lowest ID
+	if got := decode[Extremes](t, rec); got.Youngest == nil || got.Youngest.ID != 2 || got.Oldest == nil || got.Oldest.ID != 1 {
+		t.Errorf("got %s, want youngest 2, oldest 1", rec.Body)
+	}
+}
+
+func TestExtremesEmpty(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{}
+	})
+
+	rec := tc.Do(http.MethodGet, "/users/extremes", "")
+	expectStatus(t, rec, http.StatusOK)
+	if got := strings.TrimSpace(rec.Body.String()); got != `{"youngest":null,"oldest":null}` {
+		t.Errorf("body %s", got)
+	}
+}
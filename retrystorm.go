@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// repeatCount tracks identical requests from one client within a window.
+type repeatCount struct {
+	key   string
+	n     int
+	start time.Time
+}
+
+// repeatCounter holds counts by client, method, path and body hash. Every
+// window has the same length, so byStart, oldest first, is also the order
+// in which the counts expire.
+type repeatCounter struct {
+	mu      sync.Mutex
+	counts  map[string]*repeatCount
+	byStart list.List
+}
+
+// RetryStorms logs, at WARN, a client that sends the same request (method,
+// path with query, and body) threshold times within window, which usually
+// means a client retrying in a loop. Clients are told apart by their real
+// IP. The warning is logged once per window when the count reaches
+// threshold; requests are never rejected.
+func RetryStorms(window time.Duration, threshold int) echo.MiddlewareFunc {
+	counter := &repeatCounter{counts: map[string]*repeatCount{}}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			h := sha256.New()
+			if req.Body != nil {
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					return next(c)
+				}
+				req.Body = io.NopCloser(bytes.NewReader(body))
+				h.Write(body)
+			}
+
+			client := c.RealIP()
+			key := client + "\x00" + req.Method + "\x00" + req.URL.RequestURI() + "\x00" + string(h.Sum(nil))
+			if n := counter.add(key, time.Now(), window); n == threshold {
+				c.Logger().Warnj(log.JSON{
+					"message": "repeated identical requests",
+					"client":  client,
+					"method":  req.Method,
+					"uri":     req.URL.RequestURI(),
+					"count":   n,
+					"window":  window.String(),
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// add counts a request for key at now and returns the count in its current
+// window. Counts whose window has passed are dropped on the way, from the
+// front of byStart.
+func (rc *repeatCounter) add(key string, now time.Time, window time.Duration) int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for front := rc.byStart.Front(); front != nil; front = rc.byStart.Front() {
+		e := front.Value.(*repeatCount)
+		if now.Sub(e.start) < window {
+			break
+		}
+		rc.byStart.Remove(front)
+		delete(rc.counts, e.key)
+	}
+
+	e, ok := rc.counts[key]
+	if !ok {
+		e = &repeatCount{key: key, start: now}
+		rc.counts[key] = e
+		rc.byStart.PushBack(e)
+	}
+	e.n++
+	return e.n
+}
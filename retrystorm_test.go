@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryStormsLogged(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.RetryStormThreshold = 3
+		conf.LogLevel = "warn"
+	})
+	var logs bytes.Buffer
+	tc.e.Logger.SetOutput(&logs)
+
+	for range 2 {
+		tc.Do(http.MethodGet, "/users/1", "")
+	}
+	if strings.Contains(logs.String(), "repeated identical requests") {
+		t.Fatalf("warned below the threshold: %s", logs.String())
+	}
+	tc.Do(http.MethodGet, "/users/1", "")
+	if !strings.Contains(logs.String(), "repeated identical requests") {
+		t.Errorf("no warning at the threshold")
+	}
+}
+
+func TestRepeatCounterExpires(t *testing.T) {
+	rc := &repeatCounter{counts: map[string]*repeatCount{}}
+	start := time.Now()
+
+	rc.add("a", start, time.Second)
+	rc.add("b", start.Add(500*time.Millisecond), time.Second)
+	if n := rc.add("a", start.Add(900*time.Millisecond), time.Second); n != 2 {
+		t.Errorf("count %d inside the window, want 2", n)
+	}
+	if n := rc.add("a", start.Add(time.Second), time.Second); n != 1 {
+		t.Errorf("count %d after the window, want 1", n)
+	}
+	// a's old window is gone, b's is still open, a's new one is queued
+	if len(rc.counts) != 2 || rc.byStart.Len() != 2 {
+		t.Errorf("%d counts, %d queued; want 2, 2", len(rc.counts), rc.byStart.Len())
+	}
+}
+
+func TestRetryStormsKeyedByRequest(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.RetryStormThreshold = 2
+		conf.LogLevel = "warn"
+	})
+	var logs bytes.Buffer
+	tc.e.Logger.SetOutput(&logs)
+
+	// same path, different bodies or client: counted apart
+	tc.Do(http.MethodPost, "/users/validate", `{"name":"Dewi","age":31}`)
+	tc.Do(http.MethodPost, "/users/validate", `{"name":"Dewi","age":32}`)
+	tc.Do(http.MethodPost, "/users/validate", `{"name":"Dewi","age":31}`, "X-Real-IP", "203.0.113.9")
+	if strings.Contains(logs.String(), "repeated identical requests") {
+		t.Fatalf("warned for different requests: %s", logs.String())
+	}
+
+	// warned once at the threshold, not again past it, and the handler
+	// still saw the body
+	for range 3 {
+		rec := tc.Do(http.MethodPost, "/users/validate", `{"name":"Dewi","age":32}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d, body %s", rec.Code, rec.Body)
+		}
+	}
+	if n := strings.Count(logs.String(), "repeated identical requests"); n != 1 {
+		t.Errorf("%d warnings, want 1: %s", n, logs.String())
+	}
+	if !strings.Contains(logs.String(), `"uri":"/users/validate"`) {
+		t.Errorf("warning does not name the URI: %s", logs.String())
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// JSONSchema is the subset of JSON Schema (draft 2020-12) GetUserSchema
+// produces.
+type JSONSchema struct {
+	Schema               string                     `json:"$schema"`
+	Type                 string                     `json:"type"`
+	Properties           map[string]*SchemaProperty `json:"properties"`
+	Required             []string                   `json:"required,omitempty"`
+	AdditionalProperties bool                       `json:"additionalProperties"`
+}
+
+// SchemaProperty describes one editable field.
+type SchemaProperty struct {
+	Type      string      `json:"type"`
+	MinLength *int        `json:"minLength,omitempty"`
+	MaxLength *int        `json:"maxLength,omitempty"`
+	Minimum   *int        `json:"minimum,omitempty"`
+	Maximum   *int        `json:"maximum,omitempty"`
+	Default   interface{} `json:"default,omitempty"`
+}
+
+// editableSchema derives a schema from the validate tags of the struct v.
+// Fields without a validate tag are server-maintained and left out; of the
+// rules, required, min and max are translated and the rest are enforced
+// only by the server. Each property defaults to the field's value in v.
+func editableSchema(v interface{}) JSONSchema {
+	rv := reflect.ValueOf(v)
+	rt := rv.Type()
+	schema := JSONSchema{
+		Schema:     "https://json-schema.org/draft/2020-12/schema",
+		Type:       "object",
+		Properties: map[string]*SchemaProperty{},
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		rules := f.Tag.Get("validate")
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if rules == "" || name == "" || name == "-" {
+			continue
+		}
+
+		prop := &SchemaProperty{Default: rv.Field(i).Interface()}
+		isString := f.Type.Kind() == reflect.String
+		if isString {
+			prop.Type = "string"
+		} else {
+			prop.Type = "integer"
+		}
+
+		for _, rule := range strings.Split(rules, ",") {
+			tag, param, _ := strings.Cut(rule, "=")
+			n, err := strconv.Atoi(param)
+			switch {
+			case tag == "required" || tag == "present":
+				schema.Required = append(schema.Required, name)
+				if isString {
+					one := 1
+					prop.MinLength = &one
+				}
+			case tag == "min" && err == nil && isString:
+				prop.MinLength = &n
+			case tag == "min" && err == nil:
+				prop.Minimum = &n
+			case tag == "max" && err == nil && isString:
+				prop.MaxLength = &n
+			case tag == "max" && err == nil:
+				prop.Maximum = &n
+			}
+		}
+		schema.Properties[name] = prop
+	}
+	return schema
+}
+
+// GetUserSchema godoc
+// @Summary      JSON Schema of a user's editable fields
+// @Description  Describes the fields of the user a client may change, with their constraints and the user's current values as defaults, for generic form builders
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  JSONSchema
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  NotFoundResponse
+// @Router       /users/{id}/json-schema [get]
+func GetUserSchema(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid user ID"})
+	}
+
+	done := timeStore(c)
+	u, err := usersFor(c).Get(id)
+	done()
+	if err != nil {
+		return storeError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, editableSchema(u))
+}
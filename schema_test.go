@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestGetUserSchema(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/users/2/json-schema", "")
+	expectStatus(t, rec, http.StatusOK)
+	schema := decode[JSONSchema](t, rec)
+
+	slices.Sort(schema.Required)
+	if !slices.Equal(schema.Required, []string{"age", "name"}) {
+		t.Errorf("required %v, want [age name]", schema.Required)
+	}
+	if len(schema.Properties) != 2 || schema.AdditionalProperties {
+		t.Errorf("properties %v, additionalProperties %v; want only name and age", schema.Properties, schema.AdditionalProperties)
+	}
+	name, age := schema.Properties["name"], schema.Properties["age"]
+	if name == nil || name.Type != "string" || name.MinLength == nil || *name.MinLength != 1 ||
+		name.MaxLength == nil || *name.MaxLength != maxNameLength || name.Default != "Bagus" {
+		t.Errorf("name %+v", name)
+	}
+	if age == nil || age.Type != "integer" || age.Minimum == nil || *age.Minimum != 0 || age.Default != 25.0 {
+		t.Errorf("age %+v", age)
+	}
+
+	expectStatus(t, tc.Do(http.MethodGet, "/users/42/json-schema", ""), http.StatusNotFound)
+}
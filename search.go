@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UserFilter selects users by name substring and age range. Every field is
+// optional; an empty filter matches all users.
+type UserFilter struct {
+	Name   string `json:"name" example:"ag"`
+	MinAge *int   `json:"min_age" validate:"omitempty,min=0" example:"18"`
+	MaxAge *int   `json:"max_age" validate:"omitempty,min=0" example:"30"`
+}
+
+// matches reports whether u satisfies every condition set on the filter.
+func (f *UserFilter) matches(u store.User) bool {
+	if f.Name != "" && !strings.Contains(strings.ToLower(u.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+	if f.MinAge != nil && u.Age < *f.MinAge {
+		return false
+	}
+	if f.MaxAge != nil && u.Age > *f.MaxAge {
+		return false
+	}
+	return true
+}
+
+// active reports whether the filter sets any condition.
+func (f *UserFilter) active() bool {
+	return f.Name != "" || f.MinAge != nil || f.MaxAge != nil
+}
+
+// invertedRange reports whether the filter's age range cannot match anyone.
+func (f *UserFilter) invertedRange() bool {
+	return f.MinAge != nil && f.MaxAge != nil && *f.MinAge > *f.MaxAge
+}
+
+// SearchRequest is the query body accepted by POST /users/search. Every
+// field is optional; an empty body matches all users.
+type SearchRequest struct {
+	UserFilter
+	Sort  string `json:"sort" example:"age,-name"`
+	Page  *int   `json:"page" example:"1"`
+	Limit *int   `json:"limit" example:"20"`
+}
+
+// sortKey is one parsed entry of a sort list such as "age,-name".
+type sortKey struct {
+	field string
+	desc  bool
+}
+
+// sortFields are the user fields that may appear in a sort list.
+var sortFields = map[string]bool{"id": true, "name": true, "age": true}
+
+// parseSort parses a comma-separated sort list. Each key is a field name,
+// optionally prefixed with "-" for descending order. Unknown keys are
+// rejected by name.
+func parseSort(raw string) ([]sortKey, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var keys []sortKey
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		key := sortKey{field: strings.TrimPrefix(part, "-"), desc: strings.HasPrefix(part, "-")}
+		if !sortFields[key.field] {
+			return nil, fmt.Errorf("unknown sort key %q", part)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// sortUsers orders list in place by keys, applied in order, breaking any
+// remaining ties by ascending ID.
+func sortUsers(list []store.User, keys []sortKey) {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		for _, key := range keys {
+			var cmp int
+			switch key.field {
+			case "name":
+				cmp = strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+			case "age":
+				cmp = a.Age - b.Age
+			case "id":
+				cmp = a.ID - b.ID
+			}
+			if key.desc {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return a.ID < b.ID
+	})
+}
+
+// SearchUsers godoc
+// @Summary      Search users
+// @Description  Filters users by name substring and age range, with sorting and pagination
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        query  body      SearchRequest  true  "Search query"
+// @Success      200    {object}  Page[store.User]
+// @Failure      400    {object}  map[string]string
+// @Failure      422    {object}  map[string]string
+// @Router       /users/search [post]
+func SearchUsers(c echo.Context) error {
+	var req SearchRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": bindErrorMessage(err)})
+	}
+
+	if err := validateRequest(c, &req); err != nil {
+		return validationError(c, err)
+	}
+
+	if req.invertedRange() {
+		return c.JSON(http.StatusUnprocessableEntity, echo.Map{"error": "min_age must not be greater than max_age"})
+	}
+
+	keys, err := parseSort(req.Sort)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	page, limit, errs := resolvePagination(req.Page, req.Limit)
+	if len(errs) > 0 {
+		return paginationError(c, errs)
+	}
+
+	done := timeStore(c)
+	list := usersFor(c).List()
+	done()
+
+	matched := []store.User{}
+	for _, u := range list {
+		if req.matches(u) {
+			matched = append(matched, u)
+		}
+	}
+	sortUsers(matched, keys)
+
+	result := paginate(matched, page, limit)
+	result.Filtered = req.active()
+	return c.JSON(http.StatusOK, result)
+}
+
+// NameSuggestion is one autocomplete hit.
+type NameSuggestion struct {
+	ID   int    `json:"id" example:"1"`
+	Name string `json:"name" example:"Agus"`
+}
+
+// autocompleteLimit is the number of suggestions returned when limit is not
+// given.
+const autocompleteLimit = 10
+
+// AutocompleteNames godoc
+// @Summary      Autocomplete user names
+// @Description  Lists users whose name starts with q (case-insensitive), sorted alphabetically
+// @Tags         users
+// @Produce      json
+// @Param        q      query     string  true   "Name prefix"
+// @Param        limit  query     int     false  "Maximum suggestions (default 10, max MAX_PAGE_LIMIT)"
+// @Success      200    {array}   NameSuggestion
+// @Failure      400    {object}  map[string]string
+// @Router       /users/autocomplete [get]
+func AutocompleteNames(c echo.Context) error {
+	q := c.QueryParam("q")
+	if q == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Missing q query parameter"})
+	}
+
+	limit, err := queryCount(c, "limit", autocompleteLimit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	done := timeStore(c)
+	list := usersFor(c).List()
+	done()
+
+	prefix := store.NameKey(q)
+	result := []NameSuggestion{}
+	for _, u := range list {
+		if strings.HasPrefix(store.NameKey(u.Name), prefix) {
+			result = append(result, NameSuggestion{ID: u.ID, Name: u.Name})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := store.NameKey(result[i].Name), store.NameKey(result[j].Name)
+		if a != b {
+			return a < b
+		}
+		return result[i].ID < result[j].ID
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// similarCount is the number of users GET /users/:id/similar returns when
+// count is not given.
+const similarCount = 5
+
+// SimilarUsers godoc
+// @Summary      Users closest in age
+// @Description  Lists up to count other users ordered by absolute age difference to the given user, then by ID
+// @Tags         users
+// @Produce      json
+// @Param        id     path      int  true   "User ID"
+// @Param        count  query     int  false  "Maximum users (default 5, max MAX_PAGE_LIMIT)"
+// @Success      200    {array}   store.User
+// @Failure      400    {object}  map[string]string
+// @Failure      404    {object}  NotFoundResponse
+// @Router       /users/{id}/similar [get]
+func SimilarUsers(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid user ID"})
+	}
+
+	count, err := queryCount(c, "count", similarCount)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	users := usersFor(c)
+	done := timeStore(c)
+	base, err := users.Get(id)
+	list := users.List()
+	done()
+	if err != nil {
+		return storeError(c, err)
+	}
+
+	distance := func(u store.User) int {
+		if u.Age > base.Age {
+			return u.Age - base.Age
+		}
+		return base.Age - u.Age
+	}
+
+	result := []store.User{}
+	for _, u := range list {
+		if u.ID != base.ID {
+			result = append(result, u)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if di, dj := distance(result[i]), distance(result[j]); di != dj {
+			return di < dj
+		}
+		return result[i].ID < result[j].ID
+	})
+	if len(result) > count {
+		result = result[:count]
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// recentLimit is the number of users GET /users/recent returns when limit
+// is not given.
+const recentLimit = 10
+
+// RecentUsers godoc
+// @Summary      Recently created users
+// @Description  Lists the most recently created users, newest first
+// @Tags         users
+// @Produce      json
+// @Param        limit  query     int  false  "Maximum users (default 10, max MAX_PAGE_LIMIT)"
+// @Success      200    {array}   store.User
+// @Failure      400    {object}  map[string]string
+// @Router       /users/recent [get]
+func RecentUsers(c echo.Context) error {
+	limit, err := queryCount(c, "limit", recentLimit)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+
+	done := timeStore(c)
+	list := usersFor(c).List()
+	done()
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.After(list[j].CreatedAt)
+		}
+		return list[i].ID > list[j].ID
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	if list == nil {
+		// an empty store lists as [], not null
+		list = []store.User{}
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go-echo/store"
+)
+
+// names lists the names of users in order.
+func names(users []store.User) []string {
+	out := make([]string, 0, len(users))
+	for _, u := range users {
+		out = append(out, u.Name)
+	}
+	return out
+}
+
+func TestSearchUsers(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users/search", `{"name":"ag","min_age":20,"sort":"-age"}`)
+	expectStatus(t, rec, http.StatusOK)
+	page := decode[Page[store.User]](t, rec)
+	if got := names(page.Data); len(got) != 1 || got[0] != "Bagus" || !page.Filtered {
+		t.Errorf("got %v, filtered %v; want [Bagus], true", got, page.Filtered)
+	}
+
+	expectStatus(t, tc.Do(http.MethodPost, "/users/search", `{"min_age":30,"max_age":20}`), http.StatusUnprocessableEntity)
+	expectStatus(t, tc.Do(http.MethodPost, "/users/search", `{"sort":"email"}`), http.StatusBadRequest)
+}
+
+func TestAutocompleteNames(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{
+			{ID: 1, Name: "Agustina", Age: 15},
+			{ID: 2, Name: "Bagus", Age: 25},
+			{ID: 3, Name: "agus", Age: 29},
+			{ID: 4, Name: "Agung", Age: 33},
+		}
+	})
+	suggest := func(query string) []string {
+		t.Helper()
+		rec := tc.Do(http.MethodGet, "/users/autocomplete?"+query, "")
+		expectStatus(t, rec, http.StatusOK)
+		var out []string
+		for _, s := range decode[[]NameSuggestion](t, rec) {
+			out = append(out, s.Name)
+		}
+		return out
+	}
+
+	// a prefix match, so Bagus is left out
+	if got := suggest("q=AG"); !slices.Equal(got, []string{"Agung", "agus", "Agustina"}) {
+		t.Errorf("q=AG: %v", got)
+	}
+	if got := suggest("q=Ag&limit=2"); !slices.Equal(got, []string{"Agung", "agus"}) {
+		t.Errorf("q=Ag&limit=2: %v", got)
+	}
+	if rec := tc.Do(http.MethodGet, "/users/autocomplete?q=zz", ""); strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("q=zz: %s, want []", rec.Body)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users/autocomplete", ""), http.StatusBadRequest)
+}
+
+func TestSimilarUsers(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{
+			{ID: 1, Name: "Agus", Age: 30},
+			{ID: 2, Name: "Bagus", Age: 40},
+			{ID: 3, Name: "Caca", Age: 27},
+			{ID: 4, Name: "Dewi", Age: 33},
+			{ID: 5, Name: "Eka", Age: 31},
+			{ID: 6, Name: "Fajar", Age: 30},
+		}
+	})
+	similar := func(target string) []string {
+		t.Helper()
+		rec := tc.Do(http.MethodGet, target, "")
+		expectStatus(t, rec, http.StatusOK)
+		return names(decode[[]store.User](t, rec))
+	}
+
+	// Caca and Dewi are both 3 years off; the lower ID comes first
+	if got := similar("/users/1/similar?count=4"); !slices.Equal(got, []string{"Fajar", "Eka", "Caca", "Dewi"}) {
+		t.Errorf("count=4: %v", got)
+	}
+	if got := similar("/users/1/similar?count=10"); len(got) != 5 || slices.Contains(got, "Agus") {
+		t.Errorf("count=10: %v, want the 5 others", got)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users/42/similar", ""), http.StatusNotFound)
+}
+
+func TestRecentUsers(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{
+			{ID: 1, Name: "Agus", Age: 15, CreatedAt: base.Add(2 * time.Hour)},
+			{ID: 2, Name: "Bagus", Age: 25, CreatedAt: base},
+			{ID: 3, Name: "Caca", Age: 29, CreatedAt: base.Add(time.Hour)},
+		}
+	})
+	recent := func(target string) []string {
+		t.Helper()
+		rec := tc.Do(http.MethodGet, target, "")
+		expectStatus(t, rec, http.StatusOK)
+		return names(decode[[]store.User](t, rec))
+	}
+
+	if got := recent("/users/recent"); !slices.Equal(got, []string{"Agus", "Caca", "Bagus"}) {
+		t.Errorf("got %v", got)
+	}
+	tc.CreateUser(store.User{Name: "Dewi", Age: 31})
+	if got := recent("/users/recent?limit=2"); !slices.Equal(got, []string{"Dewi", "Agus"}) {
+		t.Errorf("limit=2: got %v", got)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users/recent?limit=0", ""), http.StatusBadRequest)
+}
+
+func TestRecentUsersEmpty(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{}
+	})
+
+	rec := tc.Do(http.MethodGet, "/users/recent", "")
+	expectStatus(t, rec, http.StatusOK)
+	if got := strings.TrimSpace(rec.Body.String()); got != "[]" {
+		t.Errorf("body %s, want []", got)
+	}
+}
+
+func TestUsersExistBatch(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users/exists-batch", `{"names":["agus","Zed","CACA"]}`)
+	expectStatus(t, rec, http.StatusOK)
+	got := decode[map[string]bool](t, rec)
+	if want := map[string]bool{"agus": true, "Zed": false, "CACA": true}; !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	many := make([]string, 101)
+	for i := range many {
+		many[i] = fmt.Sprint("name", i)
+	}
+	body, _ := json.Marshal(ExistsBatchRequest{Names: many})
+	expectStatus(t, tc.Do(http.MethodPost, "/users/exists-batch", string(body)), http.StatusUnprocessableEntity)
+	body, _ = json.Marshal(ExistsBatchRequest{Names: many[:100]})
+	expectStatus(t, tc.Do(http.MethodPost, "/users/exists-batch", string(body)), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodPost, "/users/exists-batch", `{"names":[]}`), http.StatusUnprocessableEntity)
+}
+
+func TestGetUsersBatch(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users/get-batch", `{"ids":[3,42,1,3]}`)
+	expectStatus(t, rec, http.StatusOK)
+	got := decode[map[string]*store.User](t, rec)
+	if len(got) != 3 || got["1"] == nil || got["1"].Name != "Agus" || got["3"] == nil || got["3"].Name != "Caca" {
+		t.Errorf("got %s", rec.Body)
+	}
+	if u, ok := got["42"]; !ok || u != nil {
+		t.Errorf("42 maps to %v (present %v), want null", u, ok)
+	}
+
+	expectStatus(t, tc.Do(http.MethodPost, "/users/get-batch", `{"ids":[]}`), http.StatusUnprocessableEntity)
+	ids := make([]int, 101)
+	body, _ := json.Marshal(GetBatchRequest{IDs: ids})
+	expectStatus(t, tc.Do(http.MethodPost, "/users/get-batch", string(body)), http.StatusUnprocessableEntity)
+}
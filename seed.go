@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"go-echo/store"
+)
+
+// loadSeed reads the JSON array of users at path to start the default
+// tenant with. Every user must have a unique, positive ID and pass the same
+// validation as a created user, including unique names. Missing timestamps
+// are set to now.
+func loadSeed(path string, now time.Time) ([]store.User, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("SEED_FILE: %w", err)
+	}
+
+	var list []store.User
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("SEED_FILE: %w", err)
+	}
+
+	// validate against a store holding the whole fixture, so unique_name
+	// catches names repeated within it
+	ctx := context.WithValue(context.Background(), storeKey{}, store.NewMemory(list, nil, nil))
+	v := newValidator()
+	ids := make(map[int]bool, len(list))
+	for i := range list {
+		u := &list[i]
+		if u.ID < 1 {
+			return nil, fmt.Errorf("SEED_FILE: user %d: id must be at least 1", i)
+		}
+		if ids[u.ID] {
+			return nil, fmt.Errorf("SEED_FILE: user %d: duplicate id %d", i, u.ID)
+		}
+		ids[u.ID] = true
+
+		if err := v.StructCtx(ctx, u); err != nil {
+			return nil, fmt.Errorf("SEED_FILE: user %d: %s", i, validationMessage(err))
+		}
+
+		if u.CreatedAt.IsZero() {
+			u.CreatedAt = now
+		}
+		if u.UpdatedAt.IsZero() {
+			u.UpdatedAt = u.CreatedAt
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go-echo/store"
+)
+
+// writeSeed writes a fixture file and points SEED_FILE at it.
+func writeSeed(t *testing.T, fixture string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "seed.json")
+	if err := os.WriteFile(path, []byte(fixture), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SEED_FILE", path)
+}
+
+func TestSeedFile(t *testing.T) {
+	writeSeed(t, `[{"id":7,"name":"Dewi","age":31},{"id":3,"name":"Eka","age":0,"created_at":"2026-01-02T03:04:05Z"}]`)
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/users", "")
+	expectStatus(t, rec, http.StatusOK)
+	page := decode[Page[store.User]](t, rec)
+	if got := names(page.Data); !slices.Equal(got, []string{"Eka", "Dewi"}) {
+		t.Errorf("users %v, want [Eka Dewi]", got)
+	}
+	if u := tc.GetUser(3); u.CreatedAt.Format("2006-01-02") != "2026-01-02" || !u.UpdatedAt.Equal(u.CreatedAt) {
+		t.Errorf("user 3 timestamps %v, %v", u.CreatedAt, u.UpdatedAt)
+	}
+	if u := tc.CreateUser(store.User{Name: "Fajar", Age: 20}); u.ID != 8 {
+		t.Errorf("next ID %d, want 8", u.ID)
+	}
+}
+
+func TestSeedFileRejected(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	for _, fixture := range []string{
+		`{"id":1}`,
+		`[{"id":0,"name":"Dewi","age":31}]`,
+		`[{"id":1,"name":"Dewi","age":31},{"id":1,"name":"Eka","age":20}]`,
+		`[{"id":1,"name":"Dewi","age":31},{"id":2,"name":"DEWI","age":20}]`,
+		`[{"id":1,"name":"","age":31}]`,
+		`[{"id":1,"name":"Dewi"}]`,
+	} {
+		writeSeed(t, fixture)
+		if _, err := loadConfig(); err == nil {
+			t.Errorf("fixture %s accepted", fixture)
+		}
+	}
+
+	t.Setenv("SEED_FILE", filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := loadConfig(); err == nil {
+		t.Error("missing fixture accepted")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+)
+
+// jsonSerializer is echo's JSON serializer with configurable HTML escaping.
+// With escapeHTML on (the default) <, > and & are written as \u003c,
+// \u003e and \u0026, which keeps responses safe to embed in HTML; turning it
+// off writes them verbatim.
+type jsonSerializer struct {
+	escapeHTML bool
+}
+
+func (s jsonSerializer) Serialize(c echo.Context, i interface{}, indent string) error {
+	enc := json.NewEncoder(c.Response())
+	enc.SetEscapeHTML(s.escapeHTML)
+	if indent != "" {
+		enc.SetIndent("", indent)
+	}
+	return enc.Encode(i)
+}
+
+func (s jsonSerializer) Deserialize(c echo.Context, i interface{}) error {
+	if err := checkJSONShape(c.Request(), i); err != nil {
+		return err
+	}
+	return echo.DefaultJSONSerializer{}.Deserialize(c, i)
+}
+
+// jsonShapeError is a body whose top-level value is an array where an
+// object is expected, or the other way around.
+type jsonShapeError struct {
+	want, got string
+}
+
+func (e *jsonShapeError) Error() string {
+	return fmt.Sprintf("request body must be a JSON %s, got %s", e.want, e.got)
+}
+
+// checkJSONShape compares the top-level JSON value of the request body with
+// the kind of v before it is decoded, since the decoder's own type errors
+// do not say what was expected. Bodies that are neither an object nor an
+// array are left to the decoder. The body is restored for decoding.
+func checkJSONShape(req *http.Request, v interface{}) error {
+	want := ""
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Struct, reflect.Map:
+		want = "object"
+	case reflect.Slice, reflect.Array:
+		want = "array"
+	default:
+		return nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	got := ""
+	if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) > 0 {
+		switch trimmed[0] {
+		case '{':
+			got = "an object"
+		case '[':
+			got = "an array"
+		}
+	}
+	if got == "" || got == "an "+want {
+		return nil
+	}
+	return &jsonShapeError{want: want, got: got}
+}
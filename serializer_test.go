@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestJSONEscapeHTML(t *testing.T) {
+	for _, tt := range []struct {
+		escape bool
+		want   string
+	}{
+		{true, `"name":"Tom \u0026 Jerry"`},
+		{false, `"name":"Tom & Jerry"`},
+	} {
+		tc := newTestClient(t, func(conf *Config) {
+			conf.JSONEscapeHTML = tt.escape
+		})
+		rec := tc.Do(http.MethodPost, "/users", `{"name":"Tom & Jerry","age":31}`)
+		expectStatus(t, rec, http.StatusCreated)
+		if !strings.Contains(rec.Body.String(), tt.want) {
+			t.Errorf("escape %v: body %s, want %s", tt.escape, rec.Body, tt.want)
+		}
+		if u := decode[UserResponse](t, rec); u.Name != "Tom & Jerry" {
+			t.Errorf("escape %v: name %q", tt.escape, u.Name)
+		}
+	}
+}
+
+func TestJSONShapeMismatch(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	for _, tt := range []struct {
+		method, target, body, contentType, want string
+	}{
+		{http.MethodPost, "/users", `[{"name":"Dewi","age":31}]`, "", "request body must be a JSON object, got an array"},
+		{http.MethodPost, "/users/get-batch", `[1,2]`, "", "request body must be a JSON object, got an array"},
+		{http.MethodPatch, "/users/1", ` {"op":"replace","path":"/age","value":16}`, mimeJSONPatch, "request body must be a JSON array, got an object"},
+	} {
+		header := []string{}
+		if tt.contentType != "" {
+			header = []string{"Content-Type", tt.contentType}
+		}
+		rec := tc.Do(tt.method, tt.target, tt.body, header...)
+		expectStatus(t, rec, http.StatusBadRequest)
+		if !strings.Contains(rec.Body.String(), tt.want) {
+			t.Errorf("%s %s: body %s, want %q", tt.method, tt.target, rec.Body, tt.want)
+		}
+	}
+
+	// the right shapes go through
+	expectStatus(t, tc.Do(http.MethodPost, "/users/get-batch", `{"ids":[1,2]}`), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `[{"op":"replace","path":"/age","value":16}]`, "Content-Type", mimeJSONPatch), http.StatusOK)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+var (
+	// inFlight is the number of requests being handled, see CountInFlight.
+	inFlight atomic.Int64
+
+	// draining is closed when shutdown begins, to end requests such as
+	// event streams that would otherwise run until the client leaves.
+	draining      = make(chan struct{})
+	startDraining = sync.OnceFunc(func() { close(draining) })
+)
+
+// CountInFlight keeps inFlight up to date, for the shutdown report.
+func CountInFlight(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+		return next(c)
+	}
+}
+
+// serve runs start until it fails or the process gets SIGINT or SIGTERM,
+// then shuts e down: new connections are refused and in-flight requests get
+// up to timeout to finish. Requests still running at the deadline have
+// their connections closed, and their number is logged as forced. The
+// shutdown lines are printed whatever the log level, since they are what
+// deploy grace periods are tuned by.
+func serve(e *echo.Echo, start func() error, timeout time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() { errc <- start() }()
+	select {
+	case err := <-errc:
+		e.Logger.Fatal(err)
+	case <-ctx.Done():
+	}
+	// a second signal kills the process the default way
+	stop()
+
+	e.Logger.Printj(log.JSON{
+		"message":   "shutting down",
+		"in_flight": inFlight.Load(),
+		"timeout":   timeout.String(),
+	})
+	began := time.Now()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := e.Shutdown(shutdownCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		forced := inFlight.Load()
+		_ = e.Close()
+		e.Logger.Printj(log.JSON{
+			"message": "shutdown timed out, closed requests still in flight",
+			"forced":  forced,
+			"timeout": timeout.String(),
+		})
+		return
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		e.Logger.Fatal(err)
+	}
+	e.Logger.Printj(log.JSON{
+		"message":  "shutdown complete",
+		"duration": time.Since(began).String(),
+	})
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestShutdownReportsForcedRequests(t *testing.T) {
+	tc := newTestClient(t, nil)
+	tc.e.HideBanner, tc.e.HidePort = true, true
+	var logs bytes.Buffer
+	tc.e.Logger.SetOutput(&logs)
+	// shutting down closes draining for good; once it has, later tests get
+	// a fresh one
+	t.Cleanup(func() {
+		select {
+		case <-draining:
+		case <-time.After(time.Second):
+			t.Error("draining was not closed")
+			return
+		}
+		draining = make(chan struct{})
+		startDraining = sync.OnceFunc(func() { close(draining) })
+	})
+
+	started := make(chan struct{})
+	tc.e.GET("/slow", func(c echo.Context) error {
+		close(started)
+		<-c.Request().Context().Done()
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serve(tc.e, starter(tc.e, cfg(), "127.0.0.1:0"), 50*time.Millisecond)
+	}()
+	var addr string
+	for deadline := time.Now().Add(time.Second); addr == "" && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if a := tc.e.ListenerAddr(); a != nil {
+			addr = a.String()
+		}
+	}
+	if addr == "" {
+		t.Fatal("server did not start")
+	}
+
+	go func() {
+		if res, err := http.Get("http://" + addr + "/slow"); err == nil {
+			res.Body.Close()
+		}
+	}()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("slow request did not arrive")
+	}
+
+	// serve is listening, so it has the signal and not the test binary
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return")
+	}
+
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		if json.Unmarshal([]byte(line), &entry) == nil {
+			lines = append(lines, entry)
+		}
+	}
+	var shutting, forced map[string]any
+	for _, entry := range lines {
+		switch entry["message"] {
+		case "shutting down":
+			shutting = entry
+		case "shutdown timed out, closed requests still in flight":
+			forced = entry
+		case "shutdown complete":
+			t.Errorf("shutdown reported complete with a request in flight")
+		}
+	}
+	if shutting == nil || shutting["in_flight"] != 1.0 || shutting["timeout"] != "50ms" {
+		t.Errorf("shutting down line %v", shutting)
+	}
+	if forced == nil || forced["forced"] != 1.0 || forced["timeout"] != "50ms" {
+		t.Errorf("forced line %v in %s", forced, logs.String())
+	}
+}
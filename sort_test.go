@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"go-echo/store"
+)
+
+func TestMultiKeySort(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{
+			{ID: 1, Name: "Citra", Age: 30},
+			{ID: 2, Name: "Agus", Age: 25},
+			{ID: 3, Name: "Dewi", Age: 30},
+			{ID: 4, Name: "Bagus", Age: 25},
+		}
+	})
+
+	rec := tc.Do(http.MethodGet, "/users?sort=age,-name", "")
+	expectStatus(t, rec, http.StatusOK)
+	want := []string{"Bagus", "Agus", "Dewi", "Citra"}
+	if got := names(decode[Page[store.User]](t, rec).Data); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// ties fall back to ID order
+	rec = tc.Do(http.MethodGet, "/users?sort=-age", "")
+	want = []string{"Citra", "Dewi", "Agus", "Bagus"}
+	if got := names(decode[Page[store.User]](t, rec).Data); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	rec = tc.Do(http.MethodGet, "/users?sort=age,email", "")
+	expectStatus(t, rec, http.StatusBadRequest)
+	if !strings.Contains(rec.Body.String(), "email") {
+		t.Errorf("body %s does not name the bad key", rec.Body)
+	}
+}
+
+func TestDefaultSort(t *testing.T) {
+	for _, tt := range []struct {
+		env  string
+		want []string
+	}{
+		{"", []string{"Agus", "Bagus", "Caca"}},
+		{"-id", []string{"Caca", "Bagus", "Agus"}},
+		{"-age,name", []string{"Caca", "Bagus", "Agus"}},
+	} {
+		t.Setenv("DEFAULT_SORT", tt.env)
+		tc := newTestClient(t, nil)
+
+		rec := tc.Do(http.MethodGet, "/users", "")
+		expectStatus(t, rec, http.StatusOK)
+		if got := names(decode[Page[store.User]](t, rec).Data); !slices.Equal(got, tt.want) {
+			t.Errorf("DEFAULT_SORT=%q: got %v, want %v", tt.env, got, tt.want)
+		}
+	}
+
+	t.Setenv("DEFAULT_SORT", "email")
+	if _, err := loadConfig(); err == nil {
+		t.Error("DEFAULT_SORT=email accepted")
+	}
+}
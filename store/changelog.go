@@ -0,0 +1,85 @@
+package store
+
+import "time"
+
+// Actions recorded in the changelog.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// changelogLimit is the number of changes a store keeps; older ones are
+// dropped first.
+const changelogLimit = 10000
+
+// Change is one committed mutation of a user. Dry runs and Repair are not
+// recorded.
+type Change struct {
+	// Seq numbers the store's changes from 1, without gaps, in the order
+	// they were committed.
+	Seq    int       `json:"seq" example:"7"`
+	Action string    `json:"action" enums:"create,update,delete" example:"update"`
+	UserID int       `json:"user_id" example:"1"`
+	At     time.Time `json:"at" format:"date-time"`
+}
+
+// Changes returns a copy of the recorded changes, oldest first. Only the
+// last changelogLimit are kept.
+func (m *Memory) Changes() []Change {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]Change(nil), m.kept()...)
+}
+
+// LastSeq returns the Seq of the latest change, 0 before the first.
+func (m *Memory) LastSeq() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.seq
+}
+
+// ChangesSince returns the kept changes with a Seq above seq, oldest first,
+// and a channel that is closed once another change is recorded. Changes
+// already dropped from the log are not returned, so the first one may be
+// later than seq+1.
+func (m *Memory) ChangesSince(seq int) ([]Change, <-chan struct{}) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var since []Change
+	if kept := m.kept(); len(kept) > 0 && seq < m.seq {
+		// Seq has no gaps, so the position follows from the first kept one
+		from := seq - kept[0].Seq + 1
+		if from < 0 {
+			from = 0
+		}
+		since = append(since, kept[from:]...)
+	}
+	return since, m.recorded
+}
+
+// kept returns the last changelogLimit changes of m.changes, which may hold
+// up to twice as many; callers hold the lock.
+func (m *Memory) kept() []Change {
+	if len(m.changes) > changelogLimit {
+		return m.changes[len(m.changes)-changelogLimit:]
+	}
+	return m.changes
+}
+
+// record appends a change and wakes ChangesSince waiters; callers hold the
+// write lock. Dropped changes are trimmed in batches, once twice
+// changelogLimit have built up, so a write copies the log only every
+// changelogLimit writes rather than on each one.
+func (m *Memory) record(action string, userID int, at time.Time) {
+	m.seq++
+	m.changes = append(m.changes, Change{Seq: m.seq, Action: action, UserID: userID, At: at})
+	if len(m.changes) >= 2*changelogLimit {
+		m.changes = append(make([]Change, 0, 2*changelogLimit), m.kept()...)
+	}
+	close(m.recorded)
+	m.recorded = make(chan struct{})
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChangelogKeepsTheLastLimit(t *testing.T) {
+	m := NewMemory(nil, nil, nil)
+	// past the batch trim at twice the limit, so both the trimmed and the
+	// untrimmed backlog are seen
+	const writes = 2*changelogLimit + 5
+	for i := range writes {
+		m.mu.Lock()
+		m.record(ActionUpdate, i, time.Time{})
+		if len(m.changes) >= 2*changelogLimit {
+			t.Fatalf("after %d writes %d changes held, want fewer than %d", i+1, len(m.changes), 2*changelogLimit)
+		}
+		m.mu.Unlock()
+	}
+
+	changes := m.Changes()
+	if len(changes) != changelogLimit {
+		t.Fatalf("%d changes kept, want %d", len(changes), changelogLimit)
+	}
+	if first, last := changes[0].Seq, changes[len(changes)-1].Seq; first != writes-changelogLimit+1 || last != writes {
+		t.Errorf("kept seqs %d..%d, want %d..%d", first, last, writes-changelogLimit+1, writes)
+	}
+	if m.LastSeq() != writes {
+		t.Errorf("LastSeq = %d, want %d", m.LastSeq(), writes)
+	}
+
+	since, _ := m.ChangesSince(writes - 3)
+	if len(since) != 3 || since[0].Seq != writes-2 {
+		t.Errorf("ChangesSince(%d) = %+v, want the last 3", writes-3, since)
+	}
+	// a seq already dropped returns everything kept
+	if since, _ := m.ChangesSince(1); len(since) != changelogLimit {
+		t.Errorf("ChangesSince(1) = %d changes, want %d", len(since), changelogLimit)
+	}
+}
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned by stores. Callers match them with errors.Is.
+var (
+	// ErrNotFound means no user has the requested ID.
+	ErrNotFound = errors.New("user not found")
+	// ErrDuplicateName means the name already belongs to another user.
+	ErrDuplicateName = errors.New("name is already taken")
+	// ErrConflict means the write lost against a concurrent one it could
+	// not be applied after: the first create of a new tenant when another
+	// request created the tenant meanwhile. Retrying succeeds.
+	ErrConflict = errors.New("write conflicted with a concurrent one, retry it")
+	// ErrFull means the stores sharing a Quota hold its maximum of users.
+	ErrFull = errors.New("user limit reached")
+	// ErrTooManyTenants means a new tenant would exceed the configured
+	// maximum of tenants.
+	ErrTooManyTenants = errors.New("tenant limit reached")
+)
+
+// NotFoundError is the ErrNotFound returned for a specific ID, so callers
+// can report which lookup failed.
+type NotFoundError struct {
+	ID int
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("user %d not found", e.ID)
+}
+
+// Is makes errors.Is(err, ErrNotFound) hold for a *NotFoundError.
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
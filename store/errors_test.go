@@ -0,0 +1,35 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStoreErrors(t *testing.T) {
+	m := NewMemory(SeedUsers(time.Now()), NewQuota(4), nil)
+
+	_, err := m.Get(42)
+	var notFound *NotFoundError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &notFound) || notFound.ID != 42 {
+		t.Errorf("Get(42): %v, want a NotFoundError for 42", err)
+	}
+	if _, err := m.Delete(42, false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete(42): %v, want ErrNotFound", err)
+	}
+	if _, err := m.Create(User{Name: "BAGUS", Age: 1}, false); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("Create(BAGUS): %v, want ErrDuplicateName", err)
+	}
+	if _, err := m.Create(User{Name: "Dewi", Age: 1}, false); err != nil {
+		t.Fatalf("Create(Dewi): %v", err)
+	}
+	if _, err := m.Create(User{Name: "Eka", Age: 1}, false); !errors.Is(err, ErrFull) {
+		t.Errorf("Create past the quota: %v, want ErrFull", err)
+	}
+
+	// wrapping keeps the sentinel matchable
+	if wrapped := fmt.Errorf("saving: %w", &NotFoundError{ID: 7}); !errors.Is(wrapped, ErrNotFound) {
+		t.Error("wrapped NotFoundError does not match ErrNotFound")
+	}
+}
@@ -0,0 +1,355 @@
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+// Memory is an in-memory user store, safe for concurrent use.
+type Memory struct {
+	// mu guards users and byName; reads take the read lock and every
+	// mutation the write lock.
+	mu    sync.RWMutex
+	users []User
+	// byName maps NameKey(user.Name) to the owning user ID so uniqueness
+	// checks don't have to scan users.
+	byName map[string]int
+	// lastID is the highest ID assigned so far, also guarded by mu. IDs are
+	// never reused, even after the user holding lastID is deleted.
+	lastID int
+
+	// quota caps the users of this and the stores sharing it; nil is no
+	// cap.
+	quota *Quota
+	// changes is the changelog, also guarded by mu; seq is the number of
+	// the last change recorded.
+	changes []Change
+	seq     int
+	// recorded is closed and replaced on every change, see ChangesSince.
+	recorded chan struct{}
+
+	// displayName derives User.DisplayName from the name on every write;
+	// nil leaves it empty.
+	displayName func(string) string
+
+	// admit is set on a store handed out for a tenant that does not exist
+	// yet, see Tenants.For. Create calls it before storing the first user,
+	// to register the tenant with commit or only check that it could be
+	// without, and clears it once it succeeded. Guarded by mu.
+	admit func(commit bool) error
+}
+
+// NewMemory returns a store holding seed. A non-nil quota caps how many
+// users Create will store, counting the seed. A non-nil displayName fills
+// in DisplayName.
+func NewMemory(seed []User, quota *Quota, displayName func(string) string) *Memory {
+	m := &Memory{
+		users:       append([]User(nil), seed...),
+		byName:      make(map[string]int, len(seed)),
+		quota:       quota,
+		displayName: displayName,
+		recorded:    make(chan struct{}),
+	}
+	quota.add(len(seed))
+	for i, u := range m.users {
+		m.byName[NameKey(u.Name)] = u.ID
+		m.setDisplayName(&m.users[i])
+		m.lastID = max(m.lastID, u.ID)
+	}
+	return m
+}
+
+// List returns a copy of every user in insertion order.
+func (m *Memory) List() []User {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]User(nil), m.users...)
+}
+
+// Get returns the user with the given ID.
+func (m *Memory) Get(id int) (User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.indexOf(id)
+	if i < 0 {
+		return User{}, &NotFoundError{ID: id}
+	}
+	return m.users[i], nil
+}
+
+// GetByName returns the user whose name equals name under NameKey, or
+// ErrNotFound. Names are unique in that form, so there is at most one.
+func (m *Memory) GetByName(name string) (User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	id, ok := m.byName[NameKey(name)]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	return m.users[m.indexOf(id)], nil
+}
+
+// NameTaken reports whether name belongs to a user other than selfID.
+func (m *Memory) NameTaken(name string, selfID int) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.nameTaken(name, selfID)
+}
+
+// NamesTaken reports for each of names whether it belongs to any user,
+// keyed by the names as given.
+func (m *Memory) NamesTaken(names []string) map[string]bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	taken := make(map[string]bool, len(names))
+	for _, name := range names {
+		taken[name] = m.nameTaken(name, 0)
+	}
+	return taken
+}
+
+// Create assigns u the next ID and its timestamps and stores it. With dryRun
+// the checks run and the would-be user is returned, but nothing is stored.
+func (m *Memory) Create(u User, dryRun bool) (User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.nameTaken(u.Name, 0) {
+		return User{}, ErrDuplicateName
+	}
+	if m.quota.full() {
+		return User{}, ErrFull
+	}
+
+	u.ID = m.nextID()
+	m.setDisplayName(&u)
+	u.FieldUpdatedAt = nil
+	u.CreatedAt = time.Now().UTC()
+	u.UpdatedAt = u.CreatedAt
+
+	if dryRun {
+		if m.admit != nil {
+			if err := m.admit(false); err != nil {
+				return User{}, err
+			}
+		}
+		return u, nil
+	}
+	// another store sharing the quota may have taken the last slot
+	if !m.quota.take() {
+		return User{}, ErrFull
+	}
+	if m.admit != nil {
+		if err := m.admit(true); err != nil {
+			m.quota.add(-1)
+			return User{}, err
+		}
+		m.admit = nil
+	}
+	m.lastID = u.ID
+	m.users = append(m.users, u)
+	m.byName[NameKey(u.Name)] = u.ID
+	m.record(ActionCreate, u.ID, u.CreatedAt)
+	return u, nil
+}
+
+// Update replaces the user with the given ID by u, keeping its ID and
+// creation time.
+func (m *Memory) Update(id int, u User, dryRun bool) (User, error) {
+	return m.modify(id, dryRun, func(current User) User {
+		u.ID = current.ID
+		u.CreatedAt = current.CreatedAt
+		return u
+	})
+}
+
+// Patch applies p to the user with the given ID.
+func (m *Memory) Patch(id int, p UserPatch, dryRun bool) (User, error) {
+	return m.modify(id, dryRun, func(current User) User {
+		p.Apply(&current)
+		return current
+	})
+}
+
+// PatchMatching applies p to every user for which match returns true, all
+// or nothing, and returns the updated users. If the result would leave two
+// users with the same name, nothing changes and ErrDuplicateName is returned.
+func (m *Memory) PatchMatching(match func(User) bool, p UserPatch, dryRun bool) ([]User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+	byName := make(map[string]int, len(m.byName))
+	for key, id := range m.byName {
+		byName[key] = id
+	}
+
+	positions := []int{}
+	updated := []User{}
+	for i, current := range m.users {
+		if !match(current) {
+			continue
+		}
+		u := current
+		p.Apply(&u)
+		m.setDisplayName(&u)
+		if id, ok := byName[NameKey(u.Name)]; ok && id != u.ID {
+			return nil, ErrDuplicateName
+		}
+		u.UpdatedAt = now
+		touchChangedFields(current, &u, u.UpdatedAt)
+
+		delete(byName, NameKey(current.Name))
+		byName[NameKey(u.Name)] = u.ID
+		positions = append(positions, i)
+		updated = append(updated, u)
+	}
+	if dryRun {
+		return updated, nil
+	}
+
+	for n, i := range positions {
+		m.users[i] = updated[n]
+		m.record(ActionUpdate, updated[n].ID, now)
+	}
+	m.byName = byName
+	return updated, nil
+}
+
+// Delete removes the user with the given ID and returns it.
+func (m *Memory) Delete(id int, dryRun bool) (User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 {
+		return User{}, &NotFoundError{ID: id}
+	}
+	u := m.users[i]
+	if dryRun {
+		return u, nil
+	}
+
+	m.users = append(m.users[:i], m.users[i+1:]...)
+	m.quota.add(-1)
+	delete(m.byName, NameKey(u.Name))
+	m.record(ActionDelete, u.ID, time.Now().UTC())
+	return u, nil
+}
+
+// Merge folds the source user into the target: the target keeps its values
+// except for the fields named in takeFromSource ("name", "age"), and the
+// source is deleted. The merged target is returned.
+func (m *Memory) Merge(targetID, sourceID int, takeFromSource []string) (User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ti := m.indexOf(targetID)
+	if ti < 0 {
+		return User{}, &NotFoundError{ID: targetID}
+	}
+	si := m.indexOf(sourceID)
+	if si < 0 {
+		return User{}, &NotFoundError{ID: sourceID}
+	}
+
+	target, source := m.users[ti], m.users[si]
+	merged := target
+	for _, field := range takeFromSource {
+		switch field {
+		case "name":
+			merged.Name = source.Name
+		case "age":
+			merged.Age = source.Age
+		}
+	}
+	m.setDisplayName(&merged)
+	merged.UpdatedAt = time.Now().UTC()
+	touchChangedFields(target, &merged, merged.UpdatedAt)
+
+	m.users[ti] = merged
+	delete(m.byName, NameKey(source.Name))
+	delete(m.byName, NameKey(target.Name))
+	m.byName[NameKey(merged.Name)] = merged.ID
+	m.users = append(m.users[:si], m.users[si+1:]...)
+	m.quota.add(-1)
+	m.record(ActionUpdate, merged.ID, merged.UpdatedAt)
+	m.record(ActionDelete, source.ID, merged.UpdatedAt)
+	return merged, nil
+}
+
+// modify runs change on the user with the given ID under the write lock and
+// stores the result, maintaining timestamps and the name index.
+func (m *Memory) modify(id int, dryRun bool, change func(User) User) (User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 {
+		return User{}, &NotFoundError{ID: id}
+	}
+	current := m.users[i]
+
+	updated := change(current)
+	m.setDisplayName(&updated)
+	if m.nameTaken(updated.Name, id) {
+		return User{}, ErrDuplicateName
+	}
+	updated.UpdatedAt = time.Now().UTC()
+	touchChangedFields(current, &updated, updated.UpdatedAt)
+	if dryRun {
+		return updated, nil
+	}
+
+	m.users[i] = updated
+	delete(m.byName, NameKey(current.Name))
+	m.byName[NameKey(updated.Name)] = id
+	m.record(ActionUpdate, id, updated.UpdatedAt)
+	return updated, nil
+}
+
+// setDisplayName recomputes u.DisplayName from u.Name, discarding any
+// value the client sent.
+func (m *Memory) setDisplayName(u *User) {
+	u.DisplayName = ""
+	if m.displayName != nil {
+		u.DisplayName = m.displayName(u.Name)
+	}
+}
+
+// NextID returns the ID Create would assign right now. It reserves
+// nothing: any create in between takes it.
+func (m *Memory) NextID() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.nextID()
+}
+
+// nextID is NextID for callers already holding mu: one past the highest ID
+// ever assigned.
+func (m *Memory) nextID() int {
+	return m.lastID + 1
+}
+
+// indexOf returns the position of the user with the given ID, or -1.
+// Callers must hold mu.
+func (m *Memory) indexOf(id int) int {
+	for i, u := range m.users {
+		if u.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// nameTaken is NameTaken for callers already holding mu.
+func (m *Memory) nameTaken(name string, selfID int) bool {
+	id, ok := m.byName[NameKey(name)]
+	return ok && id != selfID
+}
@@ -0,0 +1,47 @@
+package store
+
+import "sync/atomic"
+
+// Quota is a cap on the number of users held by all the stores sharing it.
+// Methods on a nil Quota allow everything.
+type Quota struct {
+	max  int64
+	used atomic.Int64
+}
+
+// NewQuota returns a quota of maxUsers users, or nil, no cap, for zero.
+func NewQuota(maxUsers int) *Quota {
+	if maxUsers <= 0 {
+		return nil
+	}
+	return &Quota{max: int64(maxUsers)}
+}
+
+// full reports whether the quota is used up.
+func (q *Quota) full() bool {
+	return q != nil && q.used.Load() >= q.max
+}
+
+// take uses one slot, or reports false when there is none left.
+func (q *Quota) take() bool {
+	if q == nil {
+		return true
+	}
+	for {
+		used := q.used.Load()
+		if used >= q.max {
+			return false
+		}
+		if q.used.CompareAndSwap(used, used+1) {
+			return true
+		}
+	}
+}
+
+// add adjusts the slots in use by n without checking the cap, for seeds
+// and deletes.
+func (q *Quota) add(n int) {
+	if q != nil {
+		q.used.Add(int64(n))
+	}
+}
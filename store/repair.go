@@ -0,0 +1,80 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Anomaly is one problem Repair found in the stored users.
+type Anomaly struct {
+	// ID is the affected user's ID as found.
+	ID      int    `json:"id" example:"2"`
+	Problem string `json:"problem" example:"duplicate id"`
+	// Fixed reports whether Repair corrected the problem. Only duplicate
+	// IDs are fixed; everything else is flagged for a human to resolve.
+	Fixed bool `json:"fixed" example:"true"`
+	// NewID is the ID a duplicate was moved to.
+	NewID int `json:"new_id,omitempty" example:"4"`
+}
+
+// RepairReport is the result of a Repair run.
+type RepairReport struct {
+	Scanned   int       `json:"scanned" example:"3"`
+	Anomalies []Anomaly `json:"anomalies"`
+	DryRun    bool      `json:"dry_run" example:"false"`
+}
+
+// Repair checks the stored users for duplicate or non-positive IDs,
+// negative ages, blank names and names that collide under NameKey. Every
+// user after the first with a given ID, and every user with an ID below 1,
+// is moved to a fresh ID; the other anomalies are only reported. The name
+// index is rebuilt afterwards. With dryRun the report lists what would be
+// fixed and nothing changes.
+func (m *Memory) Repair(dryRun bool) RepairReport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	report := RepairReport{Scanned: len(m.users), Anomalies: []Anomaly{}, DryRun: dryRun}
+	users := append([]User(nil), m.users...)
+	next := m.nextID()
+	seenIDs := make(map[int]bool, len(users))
+	names := make(map[string]int, len(users))
+
+	for i := range users {
+		u := &users[i]
+		found := u.ID
+		switch {
+		case u.ID < 1:
+			report.Anomalies = append(report.Anomalies, Anomaly{ID: u.ID, Problem: "id must be at least 1", Fixed: true, NewID: next})
+			u.ID = next
+			next++
+		case seenIDs[u.ID]:
+			report.Anomalies = append(report.Anomalies, Anomaly{ID: u.ID, Problem: "duplicate id", Fixed: true, NewID: next})
+			u.ID = next
+			next++
+		}
+		seenIDs[u.ID] = true
+
+		if u.Age < 0 {
+			report.Anomalies = append(report.Anomalies, Anomaly{ID: found, Problem: "negative age"})
+		}
+		if strings.TrimSpace(u.Name) == "" {
+			report.Anomalies = append(report.Anomalies, Anomaly{ID: found, Problem: "blank name"})
+			continue
+		}
+		key := NameKey(u.Name)
+		if owner, ok := names[key]; ok {
+			report.Anomalies = append(report.Anomalies, Anomaly{ID: found, Problem: "name already used by user " + strconv.Itoa(owner)})
+			continue
+		}
+		names[key] = u.ID
+	}
+
+	if dryRun {
+		return report
+	}
+	m.users = users
+	m.byName = names
+	m.lastID = next - 1
+	return report
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"slices"
+	"testing"
+)
+
+// corrupted holds one of each anomaly Repair looks for.
+func corrupted() []User {
+	return []User{
+		{ID: 1, Name: "Agus", Age: 15},
+		{ID: 1, Name: "Bagus", Age: 25},
+		{ID: 0, Name: "Caca", Age: 29},
+		{ID: 2, Name: "AGUS", Age: 30},
+		{ID: 3, Name: " ", Age: -1},
+	}
+}
+
+func TestRepairDryRun(t *testing.T) {
+	m := NewMemory(corrupted(), nil, nil)
+
+	report := m.Repair(true)
+	want := []Anomaly{
+		{ID: 1, Problem: "duplicate id", Fixed: true, NewID: 4},
+		{ID: 0, Problem: "id must be at least 1", Fixed: true, NewID: 5},
+		{ID: 2, Problem: "name already used by user 1"},
+		{ID: 3, Problem: "negative age"},
+		{ID: 3, Problem: "blank name"},
+	}
+	if report.Scanned != 5 || !report.DryRun || !slices.Equal(report.Anomalies, want) {
+		t.Errorf("report %+v, want anomalies %+v", report, want)
+	}
+	if ids := userIDs(m.List()); !slices.Equal(ids, []int{1, 1, 0, 2, 3}) {
+		t.Errorf("dry run changed the IDs to %v", ids)
+	}
+}
+
+func TestRepairFixesIDs(t *testing.T) {
+	m := NewMemory(corrupted(), nil, nil)
+
+	if report := m.Repair(false); len(report.Anomalies) != 5 || report.DryRun {
+		t.Fatalf("report %+v", report)
+	}
+	if ids := userIDs(m.List()); !slices.Equal(ids, []int{1, 4, 5, 2, 3}) {
+		t.Errorf("IDs %v after repair", ids)
+	}
+	if u, err := m.Get(4); err != nil || u.Name != "Bagus" {
+		t.Errorf("Get(4) = %+v, %v", u, err)
+	}
+	if u, err := m.Create(User{Name: "Dewi", Age: 31}, false); err != nil || u.ID != 6 {
+		t.Errorf("Create after repair = %+v, %v; want ID 6", u, err)
+	}
+
+	// a second run finds only what it does not fix
+	if report := m.Repair(false); len(report.Anomalies) != 3 {
+		t.Errorf("second run %+v", report.Anomalies)
+	}
+}
+
+func userIDs(users []User) []int {
+	ids := make([]int, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	return ids
+}
@@ -0,0 +1,75 @@
+package store
+
+import "sync"
+
+// Tenants keeps one Memory store per tenant, so no tenant can see or
+// collide with another tenant's users. A tenant exists from its first
+// stored user on.
+type Tenants struct {
+	mu     sync.Mutex
+	stores map[string]*Memory
+	// maxTenants caps the tenants besides the default one; zero is no cap.
+	maxTenants  int
+	quota       *Quota
+	displayName func(string) string
+}
+
+// NewTenants returns tenant stores that together hold at most maxUsers
+// users and fill in display names with displayName, see NewMemory. A
+// positive maxTenants caps how many tenants besides the default one, "",
+// may be created. The default tenant starts out holding seed; every other
+// tenant starts empty.
+func NewTenants(seed []User, maxUsers, maxTenants int, displayName func(string) string) *Tenants {
+	quota := NewQuota(maxUsers)
+	return &Tenants{
+		stores:      map[string]*Memory{"": NewMemory(seed, quota, displayName)},
+		maxTenants:  maxTenants,
+		quota:       quota,
+		displayName: displayName,
+	}
+}
+
+// Default returns the store of the default tenant.
+func (t *Tenants) Default() *Memory {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.stores[""]
+}
+
+// For returns the store of the given tenant. A tenant that does not exist
+// yet gets an empty store of its own that is registered only when its first
+// user is stored, so reads and failed writes never create a tenant. That
+// first create fails with ErrTooManyTenants when the tenant would exceed
+// the cap, or ErrConflict when a concurrent request created the tenant
+// first.
+func (t *Tenants) For(tenant string) *Memory {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if m, ok := t.stores[tenant]; ok {
+		return m
+	}
+	m := NewMemory(nil, t.quota, t.displayName)
+	m.admit = func(commit bool) error { return t.register(tenant, m, commit) }
+	return m
+}
+
+// register adds m as the store of tenant, unless another store got there
+// first or the cap is reached. Without commit it only checks.
+func (t *Tenants) register(tenant string, m *Memory, commit bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.stores[tenant]; ok {
+		return ErrConflict
+	}
+	// the default tenant is not counted
+	if t.maxTenants > 0 && len(t.stores)-1 >= t.maxTenants {
+		return ErrTooManyTenants
+	}
+	if commit {
+		t.stores[tenant] = m
+	}
+	return nil
+}
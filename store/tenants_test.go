@@ -0,0 +1,44 @@
+package store
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTenantsForLosesRace(t *testing.T) {
+	ts := NewTenants(nil, 0, 0, nil)
+	first, second := ts.For("t1"), ts.For("t1")
+
+	if _, err := first.Create(User{Name: "a", Age: 1}, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Create(User{Name: "b", Age: 1}, false); !errors.Is(err, ErrConflict) {
+		t.Fatalf("second first write: %v, want ErrConflict", err)
+	}
+	// a retry finds the tenant the first write created
+	if _, err := ts.For("t1").Create(User{Name: "b", Age: 1}, false); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(ts.For("t1").List()); n != 2 {
+		t.Errorf("t1 holds %d users, want 2", n)
+	}
+}
+
+func TestTenantsCap(t *testing.T) {
+	ts := NewTenants(nil, 0, 1, nil)
+
+	if _, err := ts.For("t1").Create(User{Name: "a", Age: 1}, false); err != nil {
+		t.Fatal(err)
+	}
+	t2 := ts.For("t2")
+	if _, err := t2.Create(User{Name: "a", Age: 1}, true); !errors.Is(err, ErrTooManyTenants) {
+		t.Errorf("dry run past the cap: %v, want ErrTooManyTenants", err)
+	}
+	if _, err := t2.Create(User{Name: "a", Age: 1}, false); !errors.Is(err, ErrTooManyTenants) {
+		t.Errorf("create past the cap: %v, want ErrTooManyTenants", err)
+	}
+	// the default tenant is not counted
+	if _, err := ts.Default().Create(User{Name: "a", Age: 1}, false); err != nil {
+		t.Error(err)
+	}
+}
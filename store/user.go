@@ -0,0 +1,181 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+type User struct {
+	ID   int    `json:"id"`
+	Name string `json:"name" validate:"required,max=100,nocontrol,unique_name"`
+	// Age must be sent; 0 is a valid age.
+	Age int `json:"age" validate:"present,min=0"`
+
+	// DisplayName is Name title-cased for display, when DISPLAY_NAMES is
+	// on. It is maintained by the server and ignored on input; Name keeps
+	// the name as entered.
+	DisplayName string `json:"display_name,omitempty" example:"Ludwig van Beethoven"`
+
+	// CreatedAt and UpdatedAt are maintained by the server and ignored on
+	// input. UpdatedAt equals CreatedAt until the user is first modified.
+	CreatedAt time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt time.Time `json:"updated_at" format:"date-time"`
+
+	// FieldUpdatedAt records, per JSON field name, when that field last
+	// changed value. It is maintained by the server and ignored on input.
+	FieldUpdatedAt map[string]time.Time `json:"field_updated_at,omitempty"`
+
+	// ageOmitted is set by UnmarshalJSON when the body had no age, which
+	// an int cannot tell from an explicit 0.
+	ageOmitted bool
+}
+
+// Omitted reports whether the JSON field was missing (or null) in the body
+// u was decoded from. Users built any other way omit nothing.
+func (u User) Omitted(field string) bool {
+	return field == "age" && u.ageOmitted
+}
+
+// UserPatch is the body of a partial update. Nil fields are left unchanged.
+type UserPatch struct {
+	// ID is the user being patched, taken from the path. unique_name uses it
+	// to let a user keep its own name.
+	ID int `json:"-"`
+
+	Name *string `json:"name" validate:"omitnil,min=1,max=100,nocontrol,unique_name" example:"Agus"`
+	Age  *int    `json:"age" validate:"omitnil,min=0" example:"16"`
+}
+
+// Apply copies the fields set on p into u.
+func (p *UserPatch) Apply(u *User) {
+	if p.Name != nil {
+		u.Name = *p.Name
+	}
+	if p.Age != nil {
+		u.Age = *p.Age
+	}
+}
+
+// SeedUsers returns the default dataset, stamped as created at now.
+func SeedUsers(now time.Time) []User {
+	return []User{
+		{ID: 1, Name: "Agus", Age: 15, CreatedAt: now, UpdatedAt: now},
+		{ID: 2, Name: "Bagus", Age: 25, CreatedAt: now, UpdatedAt: now},
+		{ID: 3, Name: "Caca", Age: 29, CreatedAt: now, UpdatedAt: now},
+	}
+}
+
+// NameKey is the form names are compared in for uniqueness: case-folded
+// and NFC-normalized, so "José" written with a combining accent and "JOSÉ"
+// both collide with "josé". Names are stored as given.
+func NameKey(name string) string {
+	// a Caser is stateful, so don't share one between goroutines
+	return norm.NFC.String(cases.Fold().String(name))
+}
+
+// touchChangedFields copies the field timestamps of old into updated and
+// bumps the entry of every field whose value actually differs.
+func touchChangedFields(old User, updated *User, now time.Time) {
+	stamps := make(map[string]time.Time, len(old.FieldUpdatedAt)+2)
+	for field, at := range old.FieldUpdatedAt {
+		stamps[field] = at
+	}
+	if old.Name != updated.Name {
+		stamps["name"] = now
+	}
+	if old.Age != updated.Age {
+		stamps["age"] = now
+	}
+	if len(stamps) == 0 {
+		stamps = nil
+	}
+	updated.FieldUpdatedAt = stamps
+}
+
+// AgeError reports an age value in a request body that is not an integer,
+// or one too large to represent.
+type AgeError struct {
+	Value      string
+	OutOfRange bool
+}
+
+func (e *AgeError) Error() string {
+	if e.OutOfRange {
+		return fmt.Sprintf("age out of range, got %s", e.Value)
+	}
+	return fmt.Sprintf("age must be an integer, got %s", e.Value)
+}
+
+// UnmarshalJSON decodes a User, accepting Age either as a JSON number or as
+// a string holding an integer (e.g. "25"), which form-heavy clients send.
+func (u *User) UnmarshalJSON(data []byte) error {
+	type plain User
+	aux := struct {
+		*plain
+		Age json.RawMessage `json:"age"`
+	}{plain: (*plain)(u)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	u.ageOmitted = len(aux.Age) == 0 || string(aux.Age) == "null"
+	if u.ageOmitted {
+		return nil
+	}
+
+	age, err := ParseAge(aux.Age)
+	if err != nil {
+		return err
+	}
+	u.Age = age
+	return nil
+}
+
+// UnmarshalJSON decodes a UserPatch with the same Age coercion as User.
+func (p *UserPatch) UnmarshalJSON(data []byte) error {
+	type plain UserPatch
+	aux := struct {
+		*plain
+		Age json.RawMessage `json:"age"`
+	}{plain: (*plain)(p)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(aux.Age) == 0 || string(aux.Age) == "null" {
+		return nil
+	}
+
+	age, err := ParseAge(aux.Age)
+	if err != nil {
+		return err
+	}
+	p.Age = &age
+	return nil
+}
+
+// ParseAge reads a raw JSON age value that is either an integer number or a
+// string containing one. Failures are *AgeError.
+func ParseAge(raw json.RawMessage) (int, error) {
+	text := string(raw)
+	if strings.HasPrefix(text, `"`) {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return 0, &AgeError{Value: text}
+		}
+		text = strings.TrimSpace(s)
+	}
+
+	age, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, &AgeError{Value: string(raw), OutOfRange: errors.Is(err, strconv.ErrRange)}
+	}
+	return age, nil
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNameKey(t *testing.T) {
+	const composed, decomposed = "José", "José"
+	for _, name := range []string{decomposed, "JOSÉ", "josé", "JOSÉ"} {
+		if NameKey(name) != NameKey(composed) {
+			t.Errorf("NameKey(%q) = %q, want %q", name, NameKey(name), NameKey(composed))
+		}
+	}
+	if NameKey("Jose") == NameKey(composed) {
+		t.Error("Jose and José share a key")
+	}
+}
+
+func TestNormalizedUniqueness(t *testing.T) {
+	m := NewMemory(SeedUsers(time.Now()), nil, nil)
+
+	created, err := m.Create(User{Name: "José", Age: 30}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Create(User{Name: "JOSÉ", Age: 30}, false); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("decomposed, upper-case duplicate: %v, want ErrDuplicateName", err)
+	}
+	// the name is kept as it was sent
+	if got, err := m.GetByName("josé"); err != nil || got.Name != "José" || got.ID != created.ID {
+		t.Errorf("GetByName = %+v, %v", got, err)
+	}
+}
+
+func TestUserOmitted(t *testing.T) {
+	for body, omitted := range map[string]bool{
+		`{"name":"Dewi"}`:            true,
+		`{"name":"Dewi","age":null}`: true,
+		`{"name":"Dewi","age":0}`:    false,
+		`{"name":"Dewi","age":31}`:   false,
+	} {
+		var u User
+		if err := json.Unmarshal([]byte(body), &u); err != nil {
+			t.Fatal(err)
+		}
+		if u.Omitted("age") != omitted || u.Omitted("name") {
+			t.Errorf("%s: Omitted(age) %v, want %v", body, u.Omitted("age"), omitted)
+		}
+	}
+	if (User{}).Omitted("age") {
+		t.Error("a User built in code omits age")
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStrictModeChangelog(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.StrictMode = true
+	})
+
+	expectStatus(t, tc.Do(http.MethodGet, "/changelog", ""), http.StatusBadRequest)
+	expectStatus(t, tc.Do(http.MethodGet, "/changelog/tail", ""), http.StatusBadRequest)
+	expectStatus(t, tc.Do(http.MethodGet, "/changelog", "", "X-Tenant-ID", "t1"), http.StatusOK)
+}
+
+func TestStrictModeUsersHTML(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.StrictMode = true
+	})
+
+	expectStatus(t, tc.Do(http.MethodGet, "/users.html", ""), http.StatusBadRequest)
+	expectStatus(t, tc.Do(http.MethodGet, "/users.html", "", "X-Tenant-ID", "t1"), http.StatusOK)
+}
+
+func TestStrictModeRequiredHeader(t *testing.T) {
+	strict := newTestClient(t, func(conf *Config) {
+		conf.StrictMode = true
+	})
+
+	rec := strict.Do(http.MethodGet, "/users", "")
+	expectStatus(t, rec, http.StatusBadRequest)
+	if !strings.Contains(rec.Body.String(), "X-Tenant-ID") {
+		t.Errorf("body %s does not name the header", rec.Body)
+	}
+	expectStatus(t, strict.Do(http.MethodGet, "/users", "", "X-Tenant-ID", "  "), http.StatusBadRequest)
+	expectStatus(t, strict.Do(http.MethodGet, "/users", "", "X-Tenant-ID", "t1"), http.StatusOK)
+	// meta routes are not tenant-scoped
+	expectStatus(t, strict.Do(http.MethodGet, "/healthz", ""), http.StatusOK)
+
+	off := newTestClient(t, nil)
+	expectStatus(t, off.Do(http.MethodGet, "/users", ""), http.StatusOK)
+}
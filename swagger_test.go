@@ -0,0 +1,132 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/labstack/echo/v4"
+)
+
+func TestSwaggerDefaultsOffInProduction(t *testing.T) {
+	t.Setenv("APP_ENV", "production")
+	tc := newTestClient(t, nil)
+
+	for _, target := range []string{"/swagger/index.html", "/openapi.json", "/openapi.yaml"} {
+		expectStatus(t, tc.Do(http.MethodGet, target, ""), http.StatusNotFound)
+	}
+}
+
+func TestSwaggerEnabled(t *testing.T) {
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("SWAGGER_ENABLED", "true")
+	tc := newTestClient(t, nil)
+
+	for _, target := range []string{"/swagger/index.html", "/openapi.json", "/openapi.yaml"} {
+		expectStatus(t, tc.Do(http.MethodGet, target, ""), http.StatusOK)
+	}
+}
+
+// openAPISpec is the part of the generated spec the tests look at.
+type openAPISpec struct {
+	Paths map[string]map[string]struct {
+		Parameters []struct {
+			In     string `json:"in"`
+			Schema struct {
+				Ref string `json:"$ref"`
+			} `json:"schema"`
+		} `json:"parameters"`
+		Responses map[string]any `json:"responses"`
+	} `json:"paths"`
+	Definitions map[string]struct {
+		Required   []string       `json:"required"`
+		Properties map[string]any `json:"properties"`
+	} `json:"definitions"`
+}
+
+func TestSpecDocumentsPatch(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/openapi.json", "")
+	expectStatus(t, rec, http.StatusOK)
+	spec := decode[openAPISpec](t, rec)
+
+	op, ok := spec.Paths["/users/{id}"]["patch"]
+	if !ok {
+		t.Fatal("no patch operation on /users/{id}")
+	}
+	var body string
+	for _, p := range op.Parameters {
+		if p.In == "body" {
+			body = p.Schema.Ref
+		}
+	}
+	if body != "#/definitions/store.UserPatch" {
+		t.Errorf("patch body %q, want store.UserPatch", body)
+	}
+	for _, status := range []string{"200", "400", "404", "422"} {
+		if _, ok := op.Responses[status]; !ok {
+			t.Errorf("patch does not document %s", status)
+		}
+	}
+
+	// every field of the partial model is optional
+	patch := spec.Definitions["store.UserPatch"]
+	if len(patch.Required) != 0 || patch.Properties["name"] == nil || patch.Properties["age"] == nil {
+		t.Errorf("store.UserPatch = %+v", patch)
+	}
+}
+
+func TestOpenAPIDownloads(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/openapi.json", "")
+	expectStatus(t, rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != echo.MIMEApplicationJSONCharsetUTF8 {
+		t.Errorf("openapi.json Content-Type %q", ct)
+	}
+	fromJSON := decode[openAPISpec](t, rec)
+
+	rec = tc.Do(http.MethodGet, "/openapi.yaml", "")
+	expectStatus(t, rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/yaml" {
+		t.Errorf("openapi.yaml Content-Type %q", ct)
+	}
+	var fromYAML openAPISpec
+	if err := yaml.Unmarshal(rec.Body.Bytes(), &fromYAML); err != nil {
+		t.Fatalf("openapi.yaml: %v", err)
+	}
+
+	if len(fromJSON.Paths) == 0 || len(fromYAML.Paths) != len(fromJSON.Paths) {
+		t.Errorf("paths: json %d, yaml %d", len(fromJSON.Paths), len(fromYAML.Paths))
+	}
+}
+
+func TestSpecCarriesValidationConstraints(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/openapi.json", "")
+	expectStatus(t, rec, http.StatusOK)
+	spec := decode[openAPISpec](t, rec)
+
+	for _, model := range []string{"store.User", "store.UserPatch"} {
+		props := spec.Definitions[model].Properties
+		age, _ := props["age"].(map[string]any)
+		name, _ := props["name"].(map[string]any)
+		if age["minimum"] != 0.0 {
+			t.Errorf("%s age: %v, want minimum 0", model, age)
+		}
+		if name["maxLength"] != float64(maxNameLength) {
+			t.Errorf("%s name: %v, want maxLength %d", model, name, maxNameLength)
+		}
+	}
+	// swag takes required only from a required rule, which age cannot use
+	// since 0 is valid, so its description says so instead
+	if required := spec.Definitions["store.User"].Required; !slices.Equal(required, []string{"name"}) {
+		t.Errorf("store.User required %v", required)
+	}
+	if age, _ := spec.Definitions["store.User"].Properties["age"].(map[string]any); age["description"] != "Age must be sent; 0 is a valid age." {
+		t.Errorf("store.User age: %v, want it described as required", age)
+	}
+}
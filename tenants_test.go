@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-echo/store"
+)
+
+func TestMaxTenants(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.MaxTenants = 2
+	})
+	as := func(tenant string) []string { return []string{"X-Tenant-ID", tenant} }
+
+	// reads, failed writes and routes outside the tenant's data create
+	// nothing, however many tenants they name
+	for i := range 10 {
+		tenant := as(fmt.Sprint("r", i))
+		expectStatus(t, tc.Do(http.MethodGet, "/users", "", tenant...), http.StatusOK)
+		expectStatus(t, tc.Do(http.MethodGet, "/users/1", "", tenant...), http.StatusNotFound)
+		expectStatus(t, tc.Do(http.MethodGet, "/healthz", "", tenant...), http.StatusOK)
+		expectStatus(t, tc.Do(http.MethodGet, "/nowhere", "", tenant...), http.StatusNotFound)
+		expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":""}`, tenant...), http.StatusUnprocessableEntity)
+		expectStatus(t, tc.Do(http.MethodPost, "/users?dry_run=true", `{"name":"a","age":1}`, tenant...), http.StatusOK)
+	}
+
+	for i := 1; i <= 2; i++ {
+		expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"a","age":1}`, as(fmt.Sprint("t", i))...), http.StatusCreated)
+	}
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"a","age":1}`, as("t3")...), http.StatusInsufficientStorage)
+	// a preview fails the way the write would
+	expectStatus(t, tc.Do(http.MethodPost, "/users?dry_run=true", `{"name":"a","age":1}`, as("t3")...), http.StatusInsufficientStorage)
+	// but reading it is fine, and finds nothing
+	rec := tc.Do(http.MethodGet, "/users", "", as("t3")...)
+	expectStatus(t, rec, http.StatusOK)
+	if page := decode[Page[store.User]](t, rec); page.Total != 0 {
+		t.Errorf("t3 lists %d users", page.Total)
+	}
+
+	// existing tenants and the default one are still served
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"b","age":1}`, as("t1")...), http.StatusCreated)
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":31}`), http.StatusCreated)
+}
+
+func TestMaxUsersAcrossTenants(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{}
+		conf.MaxUsers = 2
+	})
+
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"a","age":1}`, "X-Tenant-ID", "t1"), http.StatusCreated)
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"b","age":1}`, "X-Tenant-ID", "t2"), http.StatusCreated)
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"c","age":1}`, "X-Tenant-ID", "t3"), http.StatusInsufficientStorage)
+
+	// a delete frees the slot for any tenant
+	expectStatus(t, tc.Do(http.MethodDelete, "/users/1", "", "X-Tenant-ID", "t1"), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"c","age":1}`, "X-Tenant-ID", "t3"), http.StatusCreated)
+}
+
+func TestMaxUsers(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.MaxUsers = 4
+	})
+
+	// three seeded users, one slot left
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":31}`), http.StatusCreated)
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"Eka","age":22}`), http.StatusInsufficientStorage)
+	expectStatus(t, tc.Do(http.MethodPost, "/users/1/clone", ""), http.StatusInsufficientStorage)
+	// a preview fails the way the write would
+	expectStatus(t, tc.Do(http.MethodPost, "/users?dry_run=true", `{"name":"Eka","age":22}`), http.StatusInsufficientStorage)
+	expectStatus(t, tc.Do(http.MethodGet, "/users/5", ""), http.StatusNotFound)
+}
+
+func TestTenantIsolation(t *testing.T) {
+	tc := newTestClient(t, nil)
+	as := func(tenant string) []string { return []string{"X-Tenant-ID", tenant} }
+
+	rec := tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":31}`, as("t1")...)
+	expectStatus(t, rec, http.StatusCreated)
+	id := decode[store.User](t, rec).ID
+
+	expectStatus(t, tc.Do(http.MethodGet, fmt.Sprint("/users/", id), "", as("t1")...), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodGet, fmt.Sprint("/users/", id), "", as("t2")...), http.StatusNotFound)
+	expectStatus(t, tc.Do(http.MethodDelete, fmt.Sprint("/users/", id), "", as("t2")...), http.StatusNotFound)
+
+	// names are unique per tenant
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":40}`, as("t2")...), http.StatusCreated)
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"dewi","age":40}`, as("t1")...), http.StatusConflict)
+
+	rec = tc.Do(http.MethodGet, "/users", "", as("t2")...)
+	if got := names(decode[Page[store.User]](t, rec).Data); len(got) != 1 || got[0] != "Dewi" {
+		t.Errorf("t2 lists %v", got)
+	}
+	// the default tenant keeps its own users
+	rec = tc.Do(http.MethodGet, "/users", "")
+	if got := decode[Page[store.User]](t, rec); got.Total != 3 {
+		t.Errorf("default tenant has %d users, want 3", got.Total)
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"errors"
+
+	"go-echo/store"
+)
+
+// bindErrorMessage picks the client-facing message for a failed Bind,
+// surfacing field errors we understand and hiding decoder internals.
+func bindErrorMessage(err error) string {
+	var ageErr *store.AgeError
+	if errors.As(err, &ageErr) {
+		return ageErr.Error()
+	}
+	var shapeErr *jsonShapeError
+	if errors.As(err, &shapeErr) {
+		return shapeErr.Error()
+	}
+	return "Invalid input"
+}
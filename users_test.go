@@ -0,0 +1,301 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-echo/store"
+)
+
+func TestFieldUpdatedAt(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `{"name":"Agustina"}`), http.StatusOK)
+	u := tc.GetUser(1)
+	if _, ok := u.FieldUpdatedAt["name"]; !ok {
+		t.Errorf("name change not stamped: %v", u.FieldUpdatedAt)
+	}
+	if _, ok := u.FieldUpdatedAt["age"]; ok {
+		t.Errorf("unchanged age stamped: %v", u.FieldUpdatedAt)
+	}
+}
+
+func TestNotFoundNamesTheID(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/users/42", "")
+	expectStatus(t, rec, http.StatusNotFound)
+	if body := decode[NotFoundResponse](t, rec); body.Error.ID != 42 || body.Error.Message == "" {
+		t.Errorf("body %s", rec.Body)
+	}
+}
+
+func TestDuplicateNameRejected(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"agus","age":20}`), http.StatusConflict)
+	// a rename frees the old name at once
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `{"name":"Agustina"}`), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"agus","age":20}`), http.StatusCreated)
+
+	// and so does a delete
+	expectStatus(t, tc.Do(http.MethodDelete, "/users/2", ""), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"Bagus","age":20}`), http.StatusCreated)
+	// while live users' names stay taken, whatever their case, by creates
+	// and renames alike
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"CACA","age":20}`), http.StatusConflict)
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `{"name":"bagus"}`), http.StatusConflict)
+}
+
+func TestCreateSetsLocation(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":31}`)
+	expectStatus(t, rec, http.StatusCreated)
+	if loc := rec.Header().Get("Location"); loc != "/users/4" {
+		t.Errorf("Location %q, want /users/4", loc)
+	}
+}
+
+func TestAgeAsString(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":" 31 "}`)
+	expectStatus(t, rec, http.StatusCreated)
+	if u := decode[UserResponse](t, rec); u.Age != 31 {
+		t.Errorf("age %d, want 31", u.Age)
+	}
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"Eka","age":"old"}`), http.StatusBadRequest)
+}
+
+func TestModifiedSince(t *testing.T) {
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tc := newTestClient(t, func(conf *Config) {
+		conf.Seed = []store.User{
+			{ID: 1, Name: "Agus", Age: 15, CreatedAt: old, UpdatedAt: old},
+			{ID: 2, Name: "Bagus", Age: 25, CreatedAt: old, UpdatedAt: old},
+		}
+	})
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/2", `{"age":26}`), http.StatusOK)
+
+	rec := tc.Do(http.MethodGet, "/users?modified_since=2021-01-01T00:00:00Z", "")
+	expectStatus(t, rec, http.StatusOK)
+	page := decode[Page[store.User]](t, rec)
+	if got := names(page.Data); len(got) != 1 || got[0] != "Bagus" || !page.Filtered {
+		t.Errorf("got %v, filtered %v; want [Bagus], true", got, page.Filtered)
+	}
+}
+
+func TestDeleteReturnsTheUser(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodDelete, "/users/2", "")
+	expectStatus(t, rec, http.StatusOK)
+	if u := decode[store.User](t, rec); u.ID != 2 || u.Name == "" {
+		t.Errorf("deleted %+v, want user 2", u)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users/2", ""), http.StatusNotFound)
+	expectStatus(t, tc.Do(http.MethodDelete, "/users/2", ""), http.StatusNotFound)
+}
+
+func TestTrailingSlash(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	for _, target := range []string{"/users", "/users/", "/users/1", "/users/1/", "/users/?limit=1"} {
+		rec := tc.Do(http.MethodGet, target, "")
+		expectStatus(t, rec, http.StatusOK)
+		if loc := rec.Header().Get("Location"); loc != "" {
+			t.Errorf("%s redirected to %s", target, loc)
+		}
+	}
+	expectStatus(t, tc.Do(http.MethodPost, "/users/", `{"name":"Dewi","age":31}`), http.StatusCreated)
+}
+
+func TestGetUserByIDCoalesced(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	// hold the first read of user 1 open until every request has joined
+	// it; they must all share its result instead of reading the store
+	const n = 20
+	release := make(chan struct{})
+	joined := make(chan struct{}, n)
+	var calls atomic.Int32
+	defer func(get func(*store.Memory, int) (store.User, error), join func()) {
+		getUser, lookupJoined = get, join
+	}(getUser, lookupJoined)
+	getUser = func(users *store.Memory, id int) (store.User, error) {
+		calls.Add(1)
+		<-release
+		return users.Get(id)
+	}
+	lookupJoined = func() { joined <- struct{}{} }
+
+	var wg sync.WaitGroup
+	recs := make([]*httptest.ResponseRecorder, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recs[i] = tc.Do(http.MethodGet, "/users/1", "")
+		}()
+	}
+	for range n {
+		<-joined
+	}
+	close(release)
+	wg.Wait()
+
+	for i, rec := range recs {
+		expectStatus(t, rec, http.StatusOK)
+		if u := decode[store.User](t, rec); u.Name != "Agus" {
+			t.Fatalf("request %d got %+v", i, u)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("%d store reads for %d requests, want 1", calls.Load(), n)
+	}
+	// once the shared read is done the next request reads the store again
+	tc.GetUser(1)
+	if calls.Load() != 2 {
+		t.Errorf("%d store reads after the shared one, want 2", calls.Load())
+	}
+}
+
+func TestCreateRejectsClientID(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	for _, target := range []string{"/users", "/users/validate", "/users?dry_run=true"} {
+		rec := tc.Do(http.MethodPost, target, `{"id":99,"name":"Dewi","age":31}`)
+		expectStatus(t, rec, http.StatusBadRequest)
+		if !strings.Contains(rec.Body.String(), "server-assigned") {
+			t.Errorf("%s: body %s", target, rec.Body)
+		}
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users/99", ""), http.StatusNotFound)
+	// an explicit zero is the same as leaving it out
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"id":0,"name":"Dewi","age":31}`), http.StatusCreated)
+}
+
+func TestOptionsAllow(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	for target, want := range map[string]string{
+		"/users":   "GET, OPTIONS, POST",
+		"/users/1": "DELETE, GET, OPTIONS, PATCH, PUT",
+	} {
+		rec := tc.Do(http.MethodOptions, target, "")
+		expectStatus(t, rec, http.StatusNoContent)
+		if got := rec.Header().Get("Allow"); got != want {
+			t.Errorf("OPTIONS %s: Allow %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestAgeOverflow(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	for _, body := range []string{
+		`{"name":"Dewi","age":9223372036854775808}`,
+		`{"name":"Dewi","age":"99999999999999999999"}`,
+	} {
+		rec := tc.Do(http.MethodPost, "/users", body)
+		expectStatus(t, rec, http.StatusBadRequest)
+		if !strings.Contains(rec.Body.String(), "age out of range") {
+			t.Errorf("%s: body %s", body, rec.Body)
+		}
+	}
+	rec := tc.Do(http.MethodPatch, "/users/1", `{"age":1e400}`)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":9223372036854775807}`)
+	expectStatus(t, rec, http.StatusCreated)
+	if u := decode[UserResponse](t, rec); u.Age != 9223372036854775807 {
+		t.Errorf("age %d", u.Age)
+	}
+}
+
+func TestGetUserByName(t *testing.T) {
+	tc := newTestClient(t, nil)
+	created := tc.CreateUser(store.User{Name: "José/Ana", Age: 31})
+
+	for _, name := range []string{"bagus", "BAGUS"} {
+		rec := tc.Do(http.MethodGet, "/users/by-name/"+name, "")
+		expectStatus(t, rec, http.StatusOK)
+		if u := decode[store.User](t, rec); u.ID != 2 {
+			t.Errorf("%s: got %+v, want user 2", name, u)
+		}
+	}
+	// decomposed, upper-case and with an escaped slash
+	rec := tc.Do(http.MethodGet, "/users/by-name/"+url.PathEscape("JOSE\u0301/ANA"), "")
+	expectStatus(t, rec, http.StatusOK)
+	if u := decode[store.User](t, rec); u.ID != created.ID {
+		t.Errorf("got %+v, want user %d", u, created.ID)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users/by-name/Zed", ""), http.StatusNotFound)
+
+	// a second match cannot come about, so the lookup stays unambiguous
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"bAgUs","age":1}`), http.StatusConflict)
+}
+
+func TestPutAgeOmittedOrZero(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	for _, body := range []string{`{"name":"Agus"}`, `{"name":"Agus","age":null}`} {
+		rec := tc.Do(http.MethodPut, "/users/1", body, "Accept", mimeProblemJSON)
+		expectStatus(t, rec, http.StatusUnprocessableEntity)
+		if errs := decode[Problem](t, rec).Errors; len(errs) != 1 || errs[0].Field != "age" || errs[0].Code != "REQUIRED" {
+			t.Errorf("PUT %s: errors %+v, want age REQUIRED", body, errs)
+		}
+	}
+	if got := tc.GetUser(1); got.Age != 15 {
+		t.Errorf("age %d after rejected PUTs, want 15 kept", got.Age)
+	}
+
+	for _, age := range []int{0, 42} {
+		rec := tc.Do(http.MethodPut, "/users/1", fmt.Sprintf(`{"name":"Agus","age":%d}`, age))
+		expectStatus(t, rec, http.StatusOK)
+		if got := tc.GetUser(1); got.Age != age {
+			t.Errorf("age %d after PUT, want %d", got.Age, age)
+		}
+	}
+
+	// POST has the same rule
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"Dewi"}`), http.StatusUnprocessableEntity)
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":0}`), http.StatusCreated)
+}
+
+func TestStoreErrorStatus(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	for _, tt := range []struct {
+		err    error
+		status int
+	}{
+		{&store.NotFoundError{ID: 7}, http.StatusNotFound},
+		{store.ErrNotFound, http.StatusNotFound},
+		{store.ErrDuplicateName, http.StatusConflict},
+		{store.ErrConflict, http.StatusConflict},
+		{store.ErrFull, http.StatusInsufficientStorage},
+		{store.ErrTooManyTenants, http.StatusInsufficientStorage},
+		{errors.New("disk on fire"), http.StatusInternalServerError},
+	} {
+		for _, err := range []error{tt.err, fmt.Errorf("saving: %w", tt.err)} {
+			rec := httptest.NewRecorder()
+			c := tc.e.NewContext(httptest.NewRequest(http.MethodPost, "/users", nil), rec)
+			if err := storeError(c, err); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.status {
+				t.Errorf("storeError(%v): status %d, want %d", err, rec.Code, tt.status)
+			}
+		}
+	}
+}
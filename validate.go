@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ValidationResult is the answer of POST /users/validate.
+type ValidationResult struct {
+	Valid  bool         `json:"valid" example:"false"`
+	Errors []FieldError `json:"errors,omitempty"`
+	// Warnings are the soft checks a valid payload still raised.
+	Warnings []FieldError `json:"warnings,omitempty"`
+}
+
+// ValidateUser godoc
+// @Summary      Validate a user payload
+// @Description  Runs the same binding and validation as CreateUser, including the name uniqueness check, without storing anything
+// @Description  Each error and warning carries a stable code to match on instead of the message: REQUIRED, MIN, MAX, ONE_OF, NO_CONTROL, UNIQUE_NAME, or AGE_WARN_ABOVE for the age warning.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        user  body      store.User         true  "User to validate"
+// @Success      200   {object}  ValidationResult
+// @Failure      400   {object}  map[string]string
+// @Failure      422   {object}  ValidationResult
+// @Router       /users/validate [post]
+func ValidateUser(c echo.Context) error {
+	var u store.User
+
+	if err := c.Bind(&u); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": bindErrorMessage(err)})
+	}
+	if u.ID != 0 {
+		return clientIDRejected(c)
+	}
+
+	if err := validateRequest(c, &u); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ValidationResult{Errors: fieldErrors(err)})
+	}
+	return c.JSON(http.StatusOK, ValidationResult{Valid: true, Warnings: userWarnings(u)})
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"go-echo/store"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// newValidator returns the validator used for request bodies with the
+// custom rules registered.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// report fields by their JSON or query names, which is what clients
+	// send
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" {
+			name = f.Tag.Get("query")
+		}
+		if name == "" || name == "-" {
+			return f.Name
+		}
+		return name
+	})
+	// optional query values are checked as the value they hold, nil when
+	// left out
+	v.RegisterCustomTypeFunc(func(f reflect.Value) any {
+		return f.Interface().(interface{ validatedValue() any }).validatedValue()
+	}, queryValue[int]{}, queryValue[time.Time]{})
+	// nocontrol rejects strings containing control characters (newlines,
+	// tabs, NUL, ...); plain spaces are fine.
+	_ = v.RegisterValidation("nocontrol", func(fl validator.FieldLevel) bool {
+		return strings.IndexFunc(fl.Field().String(), unicode.IsControl) < 0
+	})
+	_ = v.RegisterValidationCtx("unique_name", uniqueName)
+	_ = v.RegisterValidation("present", present)
+	// page_limit is the range of a page size: 1 to MAX_PAGE_LIMIT, which
+	// can change on reload
+	_ = v.RegisterValidation("page_limit", func(fl validator.FieldLevel) bool {
+		n := fl.Field().Int()
+		return n >= 1 && n <= int64(cfg().MaxPageLimit)
+	})
+	return v
+}
+
+// storeKey is the context key under which validateRequest passes the
+// request's store to unique_name.
+type storeKey struct{}
+
+// validateRequest validates i like c.Validate, but with the request's
+// tenant store in the context so unique_name checks names in that tenant.
+func validateRequest(c echo.Context, i interface{}) error {
+	cv := c.Echo().Validator.(*CustomValidator)
+	ctx := context.WithValue(c.Request().Context(), storeKey{}, usersFor(c))
+	return cv.validator.StructCtx(ctx, i)
+}
+
+// skipUniqueNameKey is the context key that turns unique_name off.
+type skipUniqueNameKey struct{}
+
+// validateBulkChanges validates i like validateRequest but without
+// unique_name, for changes applied to many users at once: the rule knows
+// no single ID to exempt, and PatchMatching checks the resulting names
+// under its lock anyway.
+func validateBulkChanges(c echo.Context, i interface{}) error {
+	cv := c.Echo().Validator.(*CustomValidator)
+	ctx := context.WithValue(c.Request().Context(), skipUniqueNameKey{}, true)
+	return cv.validator.StructCtx(ctx, i)
+}
+
+// uniqueName is the unique_name rule: the name must not belong to any user
+// other than the one being validated, identified by the ID field of the
+// enclosing struct (zero on create). Names are checked in the store passed
+// by validateRequest, or the default tenant's without one.
+//
+// The answer can be stale by the time the write happens, so the store
+// re-checks the name under its own lock.
+func uniqueName(ctx context.Context, fl validator.FieldLevel) bool {
+	if skip, _ := ctx.Value(skipUniqueNameKey{}).(bool); skip {
+		return true
+	}
+	selfID := 0
+	if id := fl.Parent().FieldByName("ID"); id.IsValid() && id.Kind() == reflect.Int {
+		selfID = int(id.Int())
+	}
+
+	users, ok := ctx.Value(storeKey{}).(*store.Memory)
+	if !ok {
+		users = tenants.Default()
+	}
+	return !users.NameTaken(fl.Field().String(), selfID)
+}
+
+// present is the rule for required fields whose zero value is valid, such
+// as an age of 0: the field must have been in the request body. It asks the
+// enclosing struct through an Omitted(field) method; structs without one
+// always pass.
+func present(fl validator.FieldLevel) bool {
+	parent := fl.Parent()
+	if !parent.CanInterface() {
+		return true
+	}
+	o, ok := parent.Interface().(interface{ Omitted(field string) bool })
+	return !ok || !o.Omitted(fl.FieldName())
+}
+
+// validationStatus picks the status for a c.Validate failure: 409 when the
+// only problem is a taken name, 422 otherwise. The body was well-formed by
+// then; 400 is kept for bind and parse errors.
+func validationStatus(err error) int {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return http.StatusUnprocessableEntity
+	}
+	for _, fe := range verrs {
+		if fe.Tag() != "unique_name" {
+			return http.StatusUnprocessableEntity
+		}
+	}
+	return http.StatusConflict
+}
+
+// FieldError describes one failed rule on one request field. Code is the
+// stable identifier to match on, see ruleCodes; Message is for people and
+// may change.
+type FieldError struct {
+	Field   string `json:"field" example:"name"`
+	Rule    string `json:"rule" example:"required"`
+	Code    string `json:"code" example:"REQUIRED"`
+	Message string `json:"message" example:"name is required"`
+}
+
+// ruleCodes is the catalog of error codes by validator rule:
+//
+//	REQUIRED        the field is missing or empty (rules required, present)
+//	MIN, MAX        a number, length or item count is out of bounds
+//	ONE_OF          the value is not one of the allowed values
+//	NO_CONTROL      the string contains control characters
+//	UNIQUE_NAME     the name belongs to another user, or to an earlier
+//	                row of a CSV import
+//	INTEGER         (CSV import) the age is not an integer
+//	COLUMNS         (CSV import) the row has the wrong number of fields
+//	AGE_WARN_ABOVE  (warning) the age is above AGE_WARN_ABOVE
+//
+// Rules missing here get their tag in upper case.
+var ruleCodes = map[string]string{
+	"required":       "REQUIRED",
+	"present":        "REQUIRED",
+	"min":            "MIN",
+	"max":            "MAX",
+	"oneof":          "ONE_OF",
+	"nocontrol":      "NO_CONTROL",
+	"unique_name":    "UNIQUE_NAME",
+	"integer":        "INTEGER",
+	"columns":        "COLUMNS",
+	"age_warn_above": "AGE_WARN_ABOVE",
+}
+
+// ruleCode returns the error code for a validator rule.
+func ruleCode(rule string) string {
+	if code, ok := ruleCodes[rule]; ok {
+		return code
+	}
+	return strings.ToUpper(rule)
+}
+
+// fieldErrors flattens a c.Validate failure into one FieldError per failed
+// rule. It returns nil for errors that are not validation errors.
+func fieldErrors(err error) []FieldError {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+
+	out := make([]FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		out = append(out, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Code:    ruleCode(fe.Tag()),
+			Message: fe.Field() + " " + ruleMessage(fe),
+		})
+	}
+	return out
+}
+
+// ruleMessage words a failed rule as a predicate on the field name.
+func ruleMessage(fe validator.FieldError) string {
+	unit := ""
+	if fe.Kind() == reflect.String {
+		unit = " characters"
+	}
+
+	switch fe.Tag() {
+	case "required", "present":
+		return "is required"
+	case "min":
+		if isList(fe.Kind()) {
+			return fmt.Sprintf("must contain at least %s items", fe.Param())
+		}
+		if unit != "" {
+			return fmt.Sprintf("must be at least %s%s long", fe.Param(), unit)
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if isList(fe.Kind()) {
+			return fmt.Sprintf("must contain at most %s items", fe.Param())
+		}
+		if unit != "" {
+			return fmt.Sprintf("must be at most %s%s long", fe.Param(), unit)
+		}
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "nocontrol":
+		return "must not contain control characters"
+	case "unique_name":
+		return "is already taken"
+	case "page_limit":
+		return fmt.Sprintf("must be between 1 and %d", cfg().MaxPageLimit)
+	default:
+		return fmt.Sprintf("failed the %s rule", fe.Tag())
+	}
+}
+
+func isList(k reflect.Kind) bool {
+	return k == reflect.Slice || k == reflect.Array
+}
+
+// validationMessage renders a c.Validate failure as a single line for the
+// error envelope.
+func validationMessage(err error) string {
+	fields := fieldErrors(err)
+	if fields == nil {
+		return err.Error()
+	}
+
+	msgs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		msgs = append(msgs, f.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// validationError writes the error response for a failed validateRequest,
+// with the status from validationStatus. The individual field errors are
+// kept for ProblemDetails' errors extension.
+func validationError(c echo.Context, err error) error {
+	if fields := fieldErrors(err); fields != nil {
+		c.Set(problemErrorsKey, fields)
+	}
+	return c.JSON(validationStatus(err), echo.Map{"error": validationMessage(err)})
+}
+
+// UserResponse is a written user together with the warnings its values
+// raised. Warnings flag values that are allowed but suspicious; they never
+// fail a request, and the field is left out when there are none.
+type UserResponse struct {
+	store.User
+	Warnings []FieldError `json:"warnings,omitempty"`
+}
+
+// userWarnings runs the soft checks on u. Their thresholds come from the
+// configuration; a zero threshold turns its check off.
+func userWarnings(u store.User) []FieldError {
+	var warnings []FieldError
+	if limit := cfg().AgeWarnAbove; limit > 0 && u.Age > limit {
+		warnings = append(warnings, FieldError{
+			Field:   "age",
+			Rule:    "age_warn_above",
+			Code:    ruleCode("age_warn_above"),
+			Message: fmt.Sprintf("age is above %d, which is unusual", limit),
+		})
+	}
+	return warnings
+}
+
+// withWarnings wraps u for a write response.
+func withWarnings(u store.User) UserResponse {
+	return UserResponse{User: u, Warnings: userWarnings(u)}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go-echo/store"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestNameControlCharacters(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	for _, name := range []string{`Dewi\nAyu`, `Dewi\tAyu`, `Dewi\u0000`, `Dewi\u007f`} {
+		rec := tc.Do(http.MethodPost, "/users", `{"name":"`+name+`","age":31}`)
+		expectStatus(t, rec, http.StatusUnprocessableEntity)
+		if !strings.Contains(rec.Body.String(), "control characters") {
+			t.Errorf("%s: body %s", name, rec.Body)
+		}
+	}
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `{"name":"Agus\r"}`), http.StatusUnprocessableEntity)
+	expectStatus(t, tc.Do(http.MethodPost, "/users", `{"name":"Dewi Ayu","age":31}`), http.StatusCreated)
+}
+
+func TestUniqueNameTag(t *testing.T) {
+	users := store.NewMemory(store.SeedUsers(time.Now()), nil, nil)
+	ctx := context.WithValue(context.Background(), storeKey{}, users)
+	v := newValidator()
+
+	err := v.StructCtx(ctx, store.User{Name: "AGUS", Age: 20})
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 1 || verrs[0].Tag() != "unique_name" {
+		t.Fatalf("duplicate name: err %v, want a unique_name failure", err)
+	}
+	// a user may keep its own name
+	if err := v.StructCtx(ctx, store.User{ID: 1, Name: "agus", Age: 20}); err != nil {
+		t.Errorf("own name: %v", err)
+	}
+	if err := v.StructCtx(ctx, store.User{Name: "Dewi", Age: 20}); err != nil {
+		t.Errorf("free name: %v", err)
+	}
+	if err := v.StructCtx(ctx, store.UserPatch{Name: ptr("Bagus")}); err == nil {
+		t.Error("duplicate name in a patch passed")
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestValidationStatus(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	for _, body := range []string{`{"name":"Dewi","age":31`, `{"name":"Dewi","age":"old"}`, `[]`} {
+		expectStatus(t, tc.Do(http.MethodPost, "/users", body), http.StatusBadRequest)
+	}
+	for _, body := range []string{`{"age":31}`, `{"name":"Dewi","age":-1}`, `{"name":"Dewi"}`} {
+		expectStatus(t, tc.Do(http.MethodPost, "/users", body), http.StatusUnprocessableEntity)
+	}
+	expectStatus(t, tc.Do(http.MethodPut, "/users/1", `{"name":"","age":31}`), http.StatusUnprocessableEntity)
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `{"age":-1}`), http.StatusUnprocessableEntity)
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `{"age":`), http.StatusBadRequest)
+}
+
+func TestValidateUser(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodPost, "/users/validate", `{"name":"Dewi","age":31}`)
+	expectStatus(t, rec, http.StatusOK)
+	if got := decode[ValidationResult](t, rec); !got.Valid || len(got.Errors) != 0 {
+		t.Errorf("valid payload: %s", rec.Body)
+	}
+	expectStatus(t, tc.Do(http.MethodGet, "/users/4", ""), http.StatusNotFound)
+
+	for _, tt := range []struct {
+		body, field, code string
+	}{
+		{`{"age":31}`, "name", "REQUIRED"},
+		{`{"name":"Dewi","age":-1}`, "age", "MIN"},
+		{`{"name":"AGUS","age":31}`, "name", "UNIQUE_NAME"},
+	} {
+		rec := tc.Do(http.MethodPost, "/users/validate", tt.body)
+		expectStatus(t, rec, http.StatusUnprocessableEntity)
+		got := decode[ValidationResult](t, rec)
+		if got.Valid || len(got.Errors) != 1 || got.Errors[0].Field != tt.field || got.Errors[0].Code != tt.code {
+			t.Errorf("%s: %s, want %s on %s", tt.body, rec.Body, tt.code, tt.field)
+		}
+	}
+}
+
+func TestAgeWarning(t *testing.T) {
+	tc := newTestClient(t, func(conf *Config) {
+		conf.AgeWarnAbove = 100
+	})
+
+	rec := tc.Do(http.MethodPost, "/users", `{"name":"Dewi","age":130}`)
+	expectStatus(t, rec, http.StatusCreated)
+	// not UserResponse: the embedded User's UnmarshalJSON would drop warnings
+	got := decode[struct{ Warnings []FieldError }](t, rec)
+	if len(got.Warnings) != 1 || got.Warnings[0].Field != "age" || got.Warnings[0].Code != "AGE_WARN_ABOVE" {
+		t.Errorf("warnings %+v", got.Warnings)
+	}
+	rec = tc.Do(http.MethodPatch, "/users/1", `{"age":101}`)
+	expectStatus(t, rec, http.StatusOK)
+	if len(decode[struct{ Warnings []FieldError }](t, rec).Warnings) != 1 {
+		t.Errorf("PATCH to 101 without a warning: %s", rec.Body)
+	}
+
+	rec = tc.Do(http.MethodPost, "/users", `{"name":"Eka","age":100}`)
+	expectStatus(t, rec, http.StatusCreated)
+	if strings.Contains(rec.Body.String(), "warnings") {
+		t.Errorf("warning at the threshold: %s", rec.Body)
+	}
+
+	off := newTestClient(t, func(conf *Config) {
+		conf.AgeWarnAbove = 0
+	})
+	rec = off.Do(http.MethodPost, "/users", `{"name":"Dewi","age":130}`)
+	expectStatus(t, rec, http.StatusCreated)
+	if strings.Contains(rec.Body.String(), "warnings") {
+		t.Errorf("warning while off: %s", rec.Body)
+	}
+}
+
+func TestRuleCodes(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	for _, tt := range []struct {
+		target, body, field, code string
+	}{
+		{"/users/validate", `{"age":31}`, "name", "REQUIRED"},
+		{"/users/validate", `{"name":"Dewi"}`, "age", "REQUIRED"},
+		{"/users/validate", `{"name":"Dewi","age":-1}`, "age", "MIN"},
+		{"/users/validate", `{"name":"` + strings.Repeat("a", 101) + `","age":31}`, "name", "MAX"},
+		{"/users/validate", `{"name":"De\twi","age":31}`, "name", "NO_CONTROL"},
+		{"/users/validate", `{"name":"bagus","age":31}`, "name", "UNIQUE_NAME"},
+		{"/users/1/merge", `{"source_id":2,"take_from_source":["id"]}`, "take_from_source[0]", "ONE_OF"},
+		{"/users/exists-batch", `{"names":[]}`, "names", "MIN"},
+	} {
+		rec := tc.Do(http.MethodPost, tt.target, tt.body, "Accept", mimeProblemJSON)
+		expectStatus(t, rec, http.StatusUnprocessableEntity)
+		errs := decode[Problem](t, rec).Errors
+		if tt.target == "/users/validate" {
+			errs = decode[ValidationResult](t, rec).Errors
+		}
+		if len(errs) != 1 || errs[0].Field != tt.field || errs[0].Code != tt.code {
+			t.Errorf("%s %s: errors %+v, want %s on %s", tt.target, tt.body, errs, tt.code, tt.field)
+		}
+	}
+	if got := ruleCode("email"); got != "EMAIL" {
+		t.Errorf("uncatalogued rule code %q, want EMAIL", got)
+	}
+}
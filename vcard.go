@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-echo/store"
+
+	"github.com/labstack/echo/v4"
+)
+
+// mimeVCard is the media type GET /users/:id serves a vCard under.
+const mimeVCard = "text/vcard"
+
+// vcardEscaper escapes the characters vCard 3.0 gives a meaning in text
+// values.
+var vcardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`)
+
+// renderVCard formats u as a vCard 3.0. The name goes into both FN and the
+// family-name slot of the mandatory N property, since users carry a single
+// name.
+func renderVCard(u store.User) string {
+	name := vcardEscaper.Replace(u.Name)
+	lines := []string{
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"UID:user-" + strconv.Itoa(u.ID),
+		"FN:" + name,
+		"N:" + name + ";;;;",
+		"X-AGE:" + strconv.Itoa(u.Age),
+		"REV:" + u.UpdatedAt.UTC().Format(time.RFC3339),
+		"END:VCARD",
+	}
+	for i, line := range lines {
+		lines[i] = foldVCardLine(line)
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// vcardLineOctets is the longest a vCard line may be before it is folded,
+// RFC 6350 §3.2.
+const vcardLineOctets = 75
+
+// foldVCardLine folds line into lines of at most vcardLineOctets octets,
+// each continuation starting with a space. Lines are only broken between
+// UTF-8 sequences, never inside one.
+func foldVCardLine(line string) string {
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		n := utf8.RuneLen(r)
+		if n < 0 {
+			// invalid UTF-8 comes out as the replacement character
+			n = utf8.RuneLen(utf8.RuneError)
+		}
+		if width+n > vcardLineOctets {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	return b.String()
+}
+
+// writeVCard sends u as a downloadable vCard.
+func writeVCard(c echo.Context, u store.User) error {
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="user-%d.vcf"`, u.ID))
+	return c.Blob(http.StatusOK, mimeVCard+"; charset=utf-8", []byte(renderVCard(u)))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGetUserAsVCard(t *testing.T) {
+	tc := newTestClient(t, nil)
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `{"name":"Agus, Jr"}`), http.StatusOK)
+
+	rec := tc.Do(http.MethodGet, "/users/1", "", "Accept", "text/vcard")
+	expectStatus(t, rec, http.StatusOK)
+	if ct := rec.Header().Get("Content-Type"); ct != "text/vcard; charset=utf-8" {
+		t.Errorf("Content-Type %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="user-1.vcf"` {
+		t.Errorf("Content-Disposition %q", cd)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 || lines[0] != "BEGIN:VCARD" || lines[1] != "VERSION:3.0" || lines[len(lines)-1] != "END:VCARD" {
+		t.Fatalf("not a vCard 3.0: %q", rec.Body)
+	}
+	for _, want := range []string{"UID:user-1", `FN:Agus\, Jr`, `N:Agus\, Jr;;;;`, "X-AGE:15"} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("no %s line in %q", want, rec.Body)
+		}
+	}
+
+	expectStatus(t, tc.Do(http.MethodGet, "/users/42", "", "Accept", "text/vcard"), http.StatusNotFound)
+}
+
+func TestVCardNegotiationCaching(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	asJSON := tc.Do(http.MethodGet, "/users/1", "")
+	asVCard := tc.Do(http.MethodGet, "/users/1", "", "Accept", "text/vcard")
+	for _, rec := range []*httptest.ResponseRecorder{asJSON, asVCard} {
+		expectStatus(t, rec, http.StatusOK)
+		if vary := strings.Join(rec.Header().Values("Vary"), ","); !strings.Contains(vary, "Accept") || !strings.Contains(vary, "Accept-Encoding") {
+			t.Errorf("Vary %q, want Accept and Accept-Encoding", vary)
+		}
+	}
+	jsonTag, vcardTag := asJSON.Header().Get("ETag"), asVCard.Header().Get("ETag")
+	if jsonTag == "" || jsonTag == vcardTag {
+		t.Fatalf("ETags %q and %q, want distinct", jsonTag, vcardTag)
+	}
+
+	// a cached JSON copy does not validate the vCard, nor the other way round
+	expectStatus(t, tc.Do(http.MethodGet, "/users/1", "", "Accept", "text/vcard", "If-None-Match", jsonTag), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodGet, "/users/1", "", "If-None-Match", vcardTag), http.StatusOK)
+	expectStatus(t, tc.Do(http.MethodGet, "/users/1", "", "Accept", "text/vcard", "If-None-Match", vcardTag), http.StatusNotModified)
+}
+
+func TestVCardFoldsLongLines(t *testing.T) {
+	tc := newTestClient(t, nil)
+	name := strings.Repeat("Ä", 50) + " " + strings.Repeat("b", 40)
+	expectStatus(t, tc.Do(http.MethodPatch, "/users/1", `{"name":"`+name+`"}`), http.StatusOK)
+
+	rec := tc.Do(http.MethodGet, "/users/1", "", "Accept", "text/vcard")
+	expectStatus(t, rec, http.StatusOK)
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\r\n"), "\r\n")
+	var unfolded []string
+	for _, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("fold inside a UTF-8 sequence: %q", line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+	if !slices.Contains(unfolded, "FN:"+name) || !slices.Contains(unfolded, "N:"+name+";;;;") {
+		t.Errorf("unfolded card %q lacks the full name", unfolded)
+	}
+	if len(unfolded) == len(lines) {
+		t.Error("nothing was folded")
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Build metadata, injected at link time:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
+// VersionInfo describes the running build.
+type VersionInfo struct {
+	Version   string `json:"version" example:"v1.2.0"`
+	Commit    string `json:"commit" example:"d72be01"`
+	BuildDate string `json:"build_date" example:"2026-01-02T15:04:05Z"`
+	GoVersion string `json:"go_version" example:"go1.24.2"`
+}
+
+// GetVersion godoc
+// @Summary      Build information
+// @Description  Returns the version, git commit and build date injected via ldflags, and the Go runtime version
+// @Tags         meta
+// @Produce      json
+// @Success      200  {object}  VersionInfo
+// @Router       /version [get]
+func GetVersion(c echo.Context) error {
+	return c.JSON(http.StatusOK, VersionInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+	})
+}
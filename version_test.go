@@ -0,0 +1,18 @@
+package main
+
+import (
+	"net/http"
+	"runtime"
+	"testing"
+)
+
+func TestGetVersion(t *testing.T) {
+	tc := newTestClient(t, nil)
+
+	rec := tc.Do(http.MethodGet, "/version", "")
+	expectStatus(t, rec, http.StatusOK)
+	got := decode[VersionInfo](t, rec)
+	if got.Version != version || got.Commit != commit || got.GoVersion != runtime.Version() {
+		t.Errorf("got %+v", got)
+	}
+}